@@ -0,0 +1,9 @@
+#![no_main]
+
+use libfuzzer_sys::fuzz_target;
+
+fuzz_target!(|data: &[u8]| {
+	// Don't crash.
+	_ = surrealdb::key::thing::Thing::decode(data);
+	_ = surrealdb::key::graph::Graph::decode(data);
+});
@@ -0,0 +1,37 @@
+use crate::err::Error;
+use crate::key::cf;
+use crate::kvs::Transaction;
+use crate::vs::{to_u128_be, u64_u16_to_versionstamp, Versionstamp};
+
+/// The maximum number of change feed entries removed in each pass
+const BATCH: u32 = 1000;
+
+/// Remove the change feed entries of a database which are older than the
+/// longest retention period in the database, relative to the specified
+/// versionstamp. Entries are removed in bounded batches, so that any
+/// remaining entries are removed by the transactions which commit afterwards.
+pub(crate) async fn gc(
+	tx: &mut Transaction,
+	ns: &str,
+	db: &str,
+	vs: Versionstamp,
+) -> Result<(), Error> {
+	// Get the longest retention period in the database
+	let tbs = tx.all_tb(ns, db).await?;
+	let dbs = tx.get_db(ns, db).await?;
+	let expiry = tbs
+		.iter()
+		.filter_map(|tb| tb.changefeed.as_ref())
+		.chain(dbs.changefeed.as_ref())
+		.map(|cf| cf.expiry)
+		.max()
+		.unwrap_or_default();
+	// The physical time of the versionstamp in milliseconds
+	let ms = (to_u128_be(vs) >> 16) as u64;
+	// The oldest versionstamp which is retained
+	let wm = u64_u16_to_versionstamp(ms.saturating_sub(expiry.as_millis() as u64), 0);
+	// Remove the expired change feed entries
+	let beg = cf::versionstamped_key_prefix(ns, db);
+	let end = cf::ts_prefix(ns, db, wm);
+	tx.delr(beg..end, BATCH).await
+}
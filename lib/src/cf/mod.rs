@@ -1 +1,8 @@
+pub(crate) mod gc;
 pub(crate) mod mutations;
+pub(crate) mod reader;
+pub(crate) mod writer;
+
+pub(crate) use self::gc::gc;
+pub(crate) use self::reader::read;
+pub(crate) use self::writer::Writer;
@@ -1,5 +1,6 @@
 use crate::sql::array::Array;
 use crate::sql::object::Object;
+use crate::sql::operation::Operation;
 use crate::sql::thing::Thing;
 use crate::sql::value::Value;
 use crate::vs::to_u128_be;
@@ -16,6 +17,9 @@ pub enum TableMutation {
 	// we do include it in the first field for convenience.
 	Set(Thing, Value),
 	Del(Thing),
+	// Patch holds the JSON Patch operations which turn the previous version
+	// of an updated record into the new version, to keep the payload small.
+	Patch(Thing, Vec<Operation>),
 }
 
 #[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Store, Hash)]
@@ -55,6 +59,13 @@ impl TableMutation {
 				let o = Object::from(h);
 				("delete".to_string(), Value::Object(o))
 			}
+			TableMutation::Patch(t, v) => {
+				let mut h = BTreeMap::<String, Value>::new();
+				h.insert("id".to_string(), Value::Thing(t));
+				h.insert("diff".to_string(), Value::from(v));
+				let o = Object::from(h);
+				("patch".to_string(), Value::Object(o))
+			}
 		};
 
 		let mut h = BTreeMap::<String, Value>::new();
@@ -92,6 +103,7 @@ impl Display for TableMutation {
 		match self {
 			TableMutation::Set(id, v) => write!(f, "SET {} {}", id, v),
 			TableMutation::Del(id) => write!(f, "DEL {}", id),
+			TableMutation::Patch(id, v) => write!(f, "PATCH {} {}", id, Value::from(v.clone())),
 		}
 	}
 }
@@ -142,6 +154,8 @@ mod tests {
 	#[test]
 	fn serialization() {
 		use super::*;
+		use crate::sql::idiom::Idiom;
+		use crate::sql::test::Parse;
 		use std::collections::HashMap;
 		let cs = ChangeSet(
 			[0, 0, 0, 0, 0, 0, 0, 0, 0, 1],
@@ -158,6 +172,11 @@ mod tests {
 							("note", Value::from("surreal")),
 						])))),
 					),
+					TableMutation::Patch(
+						Thing::from(("mytb".to_string(), "tobie".to_string())),
+						Value::parse("{ age: 1 }")
+							.diff(&Value::parse("{ age: 2 }"), Idiom::default()),
+					),
 					TableMutation::Del(Thing::from(("mytb".to_string(), "tobie".to_string()))),
 				],
 			)]),
@@ -166,7 +185,7 @@ mod tests {
 		let s = serde_json::to_string(&v).unwrap();
 		assert_eq!(
 			s,
-			r#"{"changes":[{"update":{"id":"mytb:tobie","note":"surreal"}},{"delete":{"id":"mytb:tobie"}}],"versionstamp":1}"#
+			r#"{"changes":[{"update":{"id":"mytb:tobie","note":"surreal"}},{"patch":{"diff":[{"op":"replace","path":"/age","value":2}],"id":"mytb:tobie"}},{"delete":{"id":"mytb:tobie"}}],"versionstamp":1}"#
 		);
 	}
 }
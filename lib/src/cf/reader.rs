@@ -0,0 +1,55 @@
+use crate::cf::mutations::{ChangeSet, DatabaseMutation, TableMutations};
+use crate::err::Error;
+use crate::key::cf;
+use crate::kvs::Transaction;
+use crate::vs::u16_u64_to_versionstamp;
+
+/// Read the change feed of a database, optionally only for the specified
+/// table, starting at the specified versionstamp. The changes are grouped
+/// into a change set for each versionstamp, and at most `limit` change sets
+/// are returned.
+pub(crate) async fn read(
+	tx: &mut Transaction,
+	ns: &str,
+	db: &str,
+	tb: Option<&str>,
+	since: Option<u64>,
+	limit: Option<u32>,
+) -> Result<Vec<ChangeSet>, Error> {
+	// Get the starting versionstamp
+	let vs = u16_u64_to_versionstamp(0, since.unwrap_or_default());
+	// Get the maximum number of change sets
+	let limit = limit.unwrap_or(u32::MAX) as usize;
+	// Prepare the output
+	let mut out: Vec<ChangeSet> = vec![];
+	// Walk over the change feed
+	let beg = cf::ts_prefix(ns, db, vs);
+	let end = cf::suffix(ns, db);
+	let mut cur = tx.cursor(beg..end);
+	// The latest versionstamp which was read
+	let mut seen = None;
+	while let Some((k, v)) = cur.next().await? {
+		let key = cf::Cf::decode(&k)?;
+		seen = Some(key.vs);
+		// Skip the changes of other tables
+		if let Some(tb) = tb {
+			if key.tb != tb {
+				continue;
+			}
+		}
+		let mutations: TableMutations = v.into();
+		match out.last_mut() {
+			// This versionstamp already has a change set
+			Some(ChangeSet(last, DatabaseMutation(v))) if *last == key.vs => v.push(mutations),
+			// Stop once enough change sets have been read
+			_ if out.len() == limit => break,
+			// Start a change set for this versionstamp
+			_ => out.push(ChangeSet(key.vs, DatabaseMutation(vec![mutations]))),
+		}
+	}
+	// Order later versionstamps after the ones which were read
+	if let Some(vs) = seen {
+		tx.observe(vs);
+	}
+	Ok(out)
+}
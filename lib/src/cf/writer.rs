@@ -0,0 +1,44 @@
+use crate::cf::mutations::{TableMutation, TableMutations};
+use std::collections::BTreeMap;
+
+// Writer buffers the changes made to tables within a transaction, so that
+// they can be written to the change feed of each database, under the
+// versionstamp of the transaction, when the transaction commits.
+#[derive(Default)]
+pub(crate) struct Writer {
+	// The buffered mutations, grouped by namespace, database and table
+	pub(crate) changes: BTreeMap<(String, String, String), TableMutations>,
+}
+
+impl Writer {
+	/// Buffer a change made to a table
+	pub(crate) fn record(&mut self, ns: &str, db: &str, tb: &str, change: TableMutation) {
+		self.changes
+			.entry((ns.to_owned(), db.to_owned(), tb.to_owned()))
+			.or_insert_with(|| TableMutations::new(tb.to_owned()))
+			.1
+			.push(change);
+	}
+	/// Check if any changes have been buffered
+	pub(crate) fn is_empty(&self) -> bool {
+		self.changes.is_empty()
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::sql::thing::Thing;
+
+	#[test]
+	fn record() {
+		let mut w = Writer::default();
+		assert!(w.is_empty());
+		let rid = Thing::from(("person".to_string(), "tobie".to_string()));
+		w.record("test", "test", "person", TableMutation::Del(rid.clone()));
+		w.record("test", "test", "person", TableMutation::Del(rid));
+		assert!(!w.is_empty());
+		let key = ("test".to_string(), "test".to_string(), "person".to_string());
+		assert_eq!(w.changes[&key].1.len(), 2);
+	}
+}
@@ -1,4 +1,5 @@
 use once_cell::sync::Lazy;
+use std::time::Duration;
 
 #[cfg(not(target_arch = "wasm32"))]
 #[allow(dead_code)]
@@ -12,6 +13,27 @@ pub static MAX_COMPUTATION_DEPTH: Lazy<u8> = Lazy::new(|| {
 	option_env!("SURREAL_MAX_COMPUTATION_DEPTH").and_then(|s| s.parse::<u8>().ok()).unwrap_or(120)
 });
 
+/// Specifies how many times a statement, which is not run within an explicit
+/// transaction, is retried when its transaction conflicts with another one.
+pub static MAX_STATEMENT_RETRIES: Lazy<u32> = Lazy::new(|| {
+	option_env!("SURREAL_MAX_STATEMENT_RETRIES").and_then(|s| s.parse::<u32>().ok()).unwrap_or(3)
+});
+
+/// Specifies the commit latency, in milliseconds, above which low priority
+/// write transactions are rejected when a transaction limit is set.
+pub static COMMIT_LATENCY_TARGET: Lazy<Duration> = Lazy::new(|| {
+	let ms = option_env!("SURREAL_COMMIT_LATENCY_TARGET")
+		.and_then(|s| s.parse::<u64>().ok())
+		.unwrap_or(500);
+	Duration::from_millis(ms)
+});
+
+/// Specifies how many values of a sequence are allocated at once, when
+/// the storage engine allows write transactions to run concurrently.
+pub static SEQUENCE_BATCH_SIZE: Lazy<i64> = Lazy::new(|| {
+	option_env!("SURREAL_SEQUENCE_BATCH_SIZE").and_then(|s| s.parse::<i64>().ok()).unwrap_or(100)
+});
+
 /// Specifies the names of parameters which can not be specified in a query.
 pub const PROTECTED_PARAM_NAMES: &[&str] = &["auth", "scope", "token", "session"];
 
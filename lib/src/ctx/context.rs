@@ -1,14 +1,19 @@
 use crate::ctx::canceller::Canceller;
 use crate::ctx::reason::Reason;
+use crate::dbs::Counter;
+use crate::dbs::CustomFunction;
+use crate::dbs::Hook;
 use crate::dbs::Notification;
+use crate::dbs::QueryCapabilities;
 use crate::idx::planner::executor::QueryExecutor;
+use crate::kvs::Datastore;
 use crate::sql::value::Value;
 use channel::Sender;
 use std::borrow::Cow;
 use std::collections::HashMap;
 use std::fmt::{self, Debug};
 use std::sync::atomic::{AtomicBool, Ordering};
-use std::sync::Arc;
+use std::sync::{Arc, Mutex};
 use std::time::Duration;
 use trice::Instant;
 
@@ -36,6 +41,24 @@ pub struct Context<'a> {
 	notifications: Option<Sender<Notification>>,
 	// An optional query executor
 	query_executors: Option<Arc<HashMap<String, QueryExecutor>>>,
+	// Stores the datastore write hook if available
+	hook: Option<Arc<dyn Hook>>,
+	// Stores the functions registered by an embedding application
+	functions: Option<Arc<HashMap<String, Arc<CustomFunction>>>>,
+	// The functionality which is allowed to be used
+	capabilities: Arc<QueryCapabilities>,
+	// Collects any non-fatal warnings for the current statement
+	warnings: Option<Arc<Mutex<Vec<String>>>>,
+	// Whether the current statement has had effects outside the datastore
+	effects: Option<Arc<AtomicBool>>,
+	// Counts the records read and written by the current statement
+	usage: Option<Arc<Counter>>,
+	// Whether the query is a dry run, which is never committed
+	dry_run: bool,
+	// Whether statements run in a transaction shared with other statements
+	explicit: bool,
+	// The datastore which the query is running against
+	datastore: Option<&'a Datastore>,
 }
 
 impl<'a> Default for Context<'a> {
@@ -65,6 +88,15 @@ impl<'a> Context<'a> {
 			cancelled: Arc::new(AtomicBool::new(false)),
 			notifications: None,
 			query_executors: None,
+			hook: None,
+			functions: None,
+			capabilities: Arc::new(QueryCapabilities::default()),
+			warnings: None,
+			effects: None,
+			usage: None,
+			dry_run: false,
+			explicit: false,
+			datastore: None,
 		}
 	}
 
@@ -77,6 +109,15 @@ impl<'a> Context<'a> {
 			cancelled: Arc::new(AtomicBool::new(false)),
 			notifications: parent.notifications.clone(),
 			query_executors: parent.query_executors.clone(),
+			hook: parent.hook.clone(),
+			functions: parent.functions.clone(),
+			capabilities: parent.capabilities.clone(),
+			warnings: parent.warnings.clone(),
+			effects: parent.effects.clone(),
+			usage: parent.usage.clone(),
+			dry_run: parent.dry_run,
+			explicit: parent.explicit,
+			datastore: parent.datastore,
 		}
 	}
 
@@ -118,6 +159,113 @@ impl<'a> Context<'a> {
 		self.notifications = chn.cloned()
 	}
 
+	/// Add the datastore write hook to the context, so that it
+	/// can be run for any records which are written.
+	pub fn add_hook(&mut self, hook: Option<&Arc<dyn Hook>>) {
+		self.hook = hook.cloned()
+	}
+
+	/// Add the functions registered with the datastore to the
+	/// context, so that they can be called from queries.
+	pub fn add_functions(&mut self, functions: &Arc<HashMap<String, Arc<CustomFunction>>>) {
+		self.functions = Some(functions.clone())
+	}
+
+	/// Add the capabilities of the datastore to the context, so
+	/// that disabled functionality can not be used by queries.
+	pub fn add_capabilities(&mut self, capabilities: &Arc<QueryCapabilities>) {
+		self.capabilities = capabilities.clone()
+	}
+
+	/// Add a collector for any warnings which are raised by
+	/// this context, or by any of its children.
+	pub fn add_warnings(&mut self, warnings: &Arc<Mutex<Vec<String>>>) {
+		self.warnings = Some(warnings.clone())
+	}
+
+	/// Check if the warnings raised by this context are collected
+	pub(crate) fn has_warnings(&self) -> bool {
+		self.warnings.is_some()
+	}
+
+	/// Raise a non-fatal warning for the statement which is running
+	pub(crate) fn add_warning(&self, warning: String) {
+		if let Some(warnings) = &self.warnings {
+			if let Ok(mut warnings) = warnings.lock() {
+				warnings.push(warning);
+			}
+		}
+	}
+
+	/// Add a flag which is set when this context, or any of
+	/// its children, has effects outside of the datastore.
+	pub fn add_effects(&mut self, effects: &Arc<AtomicBool>) {
+		self.effects = Some(effects.clone())
+	}
+
+	/// Mark the statement which is running as having external
+	/// effects, so that it is not retried if the transaction fails.
+	pub(crate) fn add_effect(&self) {
+		if let Some(effects) = &self.effects {
+			effects.store(true, Ordering::Relaxed);
+		}
+	}
+
+	/// Specify whether the query is a dry run, so that its changes
+	/// are never committed, and it has no external effects.
+	pub fn add_dry_run(&mut self, dry_run: bool) {
+		self.dry_run = dry_run
+	}
+
+	/// Check if the query is a dry run
+	pub(crate) fn is_dry_run(&self) -> bool {
+		self.dry_run
+	}
+
+	/// Specify whether statements run within a transaction which is
+	/// shared with other statements, rather than their own transaction.
+	pub(crate) fn add_explicit(&mut self, explicit: bool) {
+		self.explicit = explicit
+	}
+
+	/// Check if statements run within a shared transaction
+	pub(crate) fn is_explicit(&self) -> bool {
+		self.explicit
+	}
+
+	/// Add a counter for the records which are read and
+	/// written by this context, or by any of its children.
+	pub(crate) fn add_usage(&mut self, usage: &Arc<Counter>) {
+		self.usage = Some(usage.clone())
+	}
+
+	/// Count a record which was read from the storage engine
+	pub(crate) fn add_read(&self) {
+		if let Some(usage) = &self.usage {
+			usage.read();
+		}
+	}
+
+	/// Count a record of the specified size which was written
+	pub(crate) fn add_write(&self, bytes: usize) {
+		if let Some(usage) = &self.usage {
+			usage.write(bytes);
+		}
+	}
+
+	/// Note an index which was used to find records
+	pub(crate) fn add_index(&self, name: &str) {
+		if let Some(usage) = &self.usage {
+			usage.index(name);
+		}
+	}
+
+	/// Add the datastore to the context, so that work can be
+	/// done outside of the transaction which the query uses.
+	pub fn add_datastore(&mut self, datastore: &'a Datastore) {
+		self.datastore = Some(datastore)
+	}
+
 	/// Set the query executors
 	pub(crate) fn set_query_executors(&mut self, executors: HashMap<String, QueryExecutor>) {
 		self.query_executors = Some(Arc::new(executors));
@@ -133,6 +281,24 @@ impl<'a> Context<'a> {
 		self.notifications.clone()
 	}
 
+	/// Get the datastore write hook, if one has been registered.
+	pub fn hook(&self) -> Option<&Arc<dyn Hook>> {
+		self.hook.as_ref()
+	}
+
+	pub fn capabilities(&self) -> &QueryCapabilities {
+		&self.capabilities
+	}
+
+	/// Get the datastore which the query is running against
+	pub(crate) fn datastore(&self) -> Option<&'a Datastore> {
+		self.datastore
+	}
+
+	pub(crate) fn get_function(&self, name: &str) -> Option<&Arc<CustomFunction>> {
+		self.functions.as_ref().and_then(|f| f.get(name))
+	}
+
 	pub(crate) fn get_query_executor(&self, tb: &str) -> Option<&QueryExecutor> {
 		if let Some(qe) = &self.query_executors {
 			qe.get(tb)
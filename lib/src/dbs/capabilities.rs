@@ -0,0 +1,144 @@
+/// The functionality which queries run against a datastore are allowed
+/// to use. By default everything is allowed, but hardened deployments
+/// can switch off any functionality which they do not need.
+#[derive(Clone, Debug)]
+#[non_exhaustive]
+pub struct QueryCapabilities {
+	// Whether embedded scripting functions can be run
+	scripting: bool,
+	// Whether outbound http::* requests can be made
+	http: bool,
+	// Whether REMOVE statements can be run
+	remove: bool,
+	// Whether functions registered by the embedding application can be run
+	functions: bool,
+	// The hosts which outbound requests can be made to, or any if not set
+	hosts: Option<Vec<String>>,
+}
+
+impl Default for QueryCapabilities {
+	fn default() -> Self {
+		QueryCapabilities {
+			scripting: true,
+			http: true,
+			remove: true,
+			functions: true,
+			hosts: None,
+		}
+	}
+}
+
+impl QueryCapabilities {
+	/// Specify whether embedded scripting functions can be run
+	pub fn with_scripting(mut self, allow: bool) -> Self {
+		self.scripting = allow;
+		self
+	}
+
+	/// Specify whether outbound HTTP requests can be made
+	pub fn with_http(mut self, allow: bool) -> Self {
+		self.http = allow;
+		self
+	}
+
+	/// Specify whether REMOVE statements can be run
+	pub fn with_remove(mut self, allow: bool) -> Self {
+		self.remove = allow;
+		self
+	}
+
+	/// Specify whether functions registered with the datastore can be run
+	pub fn with_custom_functions(mut self, allow: bool) -> Self {
+		self.functions = allow;
+		self
+	}
+
+	/// Only allow outbound HTTP requests to the specified hosts
+	///
+	/// A host starting with `*.` also allows any of its subdomains.
+	pub fn with_allowed_hosts<I, S>(mut self, hosts: I) -> Self
+	where
+		I: IntoIterator<Item = S>,
+		S: Into<String>,
+	{
+		self.hosts = Some(hosts.into_iter().map(Into::into).collect());
+		self
+	}
+
+	/// Check whether embedded scripting functions can be run
+	pub fn allows_scripting(&self) -> bool {
+		self.scripting
+	}
+
+	/// Check whether outbound HTTP requests can be made
+	pub fn allows_http(&self) -> bool {
+		self.http
+	}
+
+	/// Check whether REMOVE statements can be run
+	pub fn allows_remove(&self) -> bool {
+		self.remove
+	}
+
+	/// Check whether functions registered with the datastore can be run
+	pub fn allows_custom_functions(&self) -> bool {
+		self.functions
+	}
+
+	/// Check whether outbound HTTP requests can be made to any host
+	pub fn allows_any_host(&self) -> bool {
+		self.http && self.hosts.is_none()
+	}
+
+	/// Check whether outbound HTTP requests can be made to the host
+	pub fn allows_host(&self, host: &str) -> bool {
+		self.http
+			&& match &self.hosts {
+				Some(hosts) => {
+					let host = host.to_ascii_lowercase();
+					hosts.iter().map(|v| v.to_ascii_lowercase()).any(|v| {
+						match v.strip_prefix('*') {
+							Some(v) if v.starts_with('.') => host.ends_with(v),
+							_ => host == v,
+						}
+					})
+				}
+				None => true,
+			}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn default_allows_everything() {
+		let c = QueryCapabilities::default();
+		assert!(c.allows_scripting());
+		assert!(c.allows_http());
+		assert!(c.allows_remove());
+		assert!(c.allows_custom_functions());
+		assert!(c.allows_any_host());
+		assert!(c.allows_host("surrealdb.com"));
+	}
+
+	#[test]
+	fn denied_http_denies_all_hosts() {
+		let c = QueryCapabilities::default().with_http(false);
+		assert!(!c.allows_any_host());
+		assert!(!c.allows_host("surrealdb.com"));
+	}
+
+	#[test]
+	fn allowed_hosts() {
+		let c = QueryCapabilities::default().with_allowed_hosts(["surrealdb.com", "*.example.com"]);
+		assert!(!c.allows_any_host());
+		assert!(c.allows_host("surrealdb.com"));
+		assert!(c.allows_host("SurrealDB.com"));
+		assert!(!c.allows_host("api.surrealdb.com"));
+		assert!(c.allows_host("api.example.com"));
+		assert!(!c.allows_host("example.com"));
+		assert!(!c.allows_host("badexample.com"));
+	}
+}
@@ -1,13 +1,17 @@
+use crate::cnf::MAX_STATEMENT_RETRIES;
 use crate::cnf::PROTECTED_PARAM_NAMES;
 use crate::ctx::Context;
 use crate::dbs::response::Response;
+use crate::dbs::Counter;
 use crate::dbs::Level;
 use crate::dbs::Notification;
 use crate::dbs::Options;
+use crate::dbs::Stats;
 use crate::dbs::Transaction;
 use crate::dbs::{Auth, QueryType};
 use crate::err::Error;
 use crate::kvs::Datastore;
+use crate::kvs::Priority;
 use crate::sql::paths::DB;
 use crate::sql::paths::NS;
 use crate::sql::query::Query;
@@ -15,6 +19,8 @@ use crate::sql::statement::Statement;
 use crate::sql::value::Value;
 use channel::Receiver;
 use futures::lock::Mutex;
+use rand::Rng;
+use std::sync::atomic::{AtomicBool, Ordering};
 use std::sync::Arc;
 use tracing::instrument;
 use trice::Instant;
@@ -23,14 +29,42 @@ pub(crate) struct Executor<'a> {
 	err: bool,
 	kvs: &'a Datastore,
 	txn: Option<Transaction>,
+	// The priority of the write transactions
+	pr: Priority,
+	// Whether transactions are cancelled instead of committed
+	dr: bool,
+	// Whether all of the statements run in a single transaction
+	tx: bool,
+	// Whether the statements report non-fatal warnings
+	wn: bool,
+	// Whether the statements report the records they read and wrote
+	st: bool,
+	// Whether an explicit transaction failed to begin
+	aborted: bool,
+	// The error which the explicit transaction failed to begin with
+	reason: Option<Error>,
 }
 
 impl<'a> Executor<'a> {
-	pub fn new(kvs: &'a Datastore) -> Executor<'a> {
+	pub fn new(
+		kvs: &'a Datastore,
+		pr: Priority,
+		dr: bool,
+		tx: bool,
+		wn: bool,
+		st: bool,
+	) -> Executor<'a> {
 		Executor {
 			kvs,
 			txn: None,
 			err: false,
+			pr,
+			dr,
+			tx,
+			wn,
+			st,
+			aborted: false,
+			reason: None,
 		}
 	}
 
@@ -39,21 +73,22 @@ impl<'a> Executor<'a> {
 	}
 
 	/// # Return
-	/// - true if a new transaction has begun
-	/// - false if
-	///   - couldn't create transaction (sets err flag)
-	///   - a transaction has already begun
-	async fn begin(&mut self, write: bool) -> bool {
+	/// - Ok(true) if a new transaction has begun
+	/// - Ok(false) if a transaction has already begun
+	/// - Err if the transaction couldn't be created (sets err flag)
+	async fn begin(&mut self, write: bool, opt: &Options) -> Result<bool, Error> {
 		match self.txn.as_ref() {
-			Some(_) => false,
-			None => match self.kvs.transaction(write, false).await {
-				Ok(v) => {
+			Some(_) => Ok(false),
+			None => match self.kvs.transaction_with_priority(write, false, self.pr).await {
+				Ok(mut v) => {
+					// Restrict the transaction to the authenticated tenant
+					v.guard(&opt.auth, opt.selected_ns(), opt.selected_db());
 					self.txn = Some(Arc::new(Mutex::new(v)));
-					true
+					Ok(true)
 				}
-				Err(_) => {
+				Err(e) => {
 					self.err = true;
-					false
+					Err(e)
 				}
 			},
 		}
@@ -72,6 +107,12 @@ impl<'a> Executor<'a> {
 					// Cancel and ignore any error because the error flag was
 					// already set
 					let _ = txn.cancel().await;
+				} else if self.dr {
+					// The changes of a dry run are never committed
+					if let Err(e) = txn.cancel().await {
+						self.err = true;
+						return Err(e);
+					}
 				} else if let Err(e) = txn.commit().await {
 					// Transaction failed to commit
 					//
@@ -98,10 +139,49 @@ impl<'a> Executor<'a> {
 		}
 	}
 
+	/// # Return
+	/// - true if the statement should be run again, after waiting
+	///   for an exponential backoff with full jitter
+	/// - false if
+	///   - the statement was run within an explicit transaction
+	///   - the error was not caused by a transaction conflict
+	///   - the statement had effects outside of the datastore, such
+	///     as running a hook or sending a http request
+	///   - the statement has already been retried too many times
+	async fn retry(
+		&mut self,
+		local: bool,
+		err: &Error,
+		effects: &AtomicBool,
+		retries: &mut u32,
+	) -> bool {
+		if !local || !matches!(err, Error::TxRetryable) || *retries >= *MAX_STATEMENT_RETRIES {
+			return false;
+		}
+		// Check if the statement had external effects
+		if effects.load(Ordering::Relaxed) {
+			return false;
+		}
+		// Increment the retry count
+		*retries += 1;
+		// Reset the error flag for the new transaction
+		self.err = false;
+		// Wait before retrying the statement
+		let max = 10u64 << (*retries).min(6);
+		let dur = std::time::Duration::from_millis(rand::thread_rng().gen_range(0..=max));
+		#[cfg(target_arch = "wasm32")]
+		wasmtimer::tokio::sleep(dur).await;
+		#[cfg(not(target_arch = "wasm32"))]
+		tokio::time::sleep(dur).await;
+		true
+	}
+
 	fn buf_cancel(&self, v: Response) -> Response {
 		Response {
 			time: v.time,
 			result: Err(Error::QueryCancelled),
+			warnings: v.warnings,
+			stats: v.stats,
 			query_type: QueryType::Other,
 		}
 	}
@@ -119,6 +199,8 @@ impl<'a> Executor<'a> {
 						.unwrap_or(Error::QueryNotExecuted)),
 					Err(e) => Err(e),
 				},
+				warnings: v.warnings,
+				stats: v.stats,
 				query_type: QueryType::Other,
 			},
 			_ => v,
@@ -135,7 +217,10 @@ impl<'a> Executor<'a> {
 	/// Flush notifications from a buffer channel (live queries) to the committed notification channel.
 	/// This is because we don't want to broadcast notifications to the user for failed transactions.
 	async fn flush(&self, ctx: &Context<'_>, rcv: Receiver<Notification>) {
-		if let Some(chn) = ctx.notifications() {
+		if self.dr {
+			// Nothing was committed in a dry run
+			self.clear(ctx, rcv).await
+		} else if let Some(chn) = ctx.notifications() {
 			while let Ok(v) = rcv.try_recv() {
 				let _ = chn.send(v).await;
 			}
@@ -151,6 +236,7 @@ impl<'a> Executor<'a> {
 		session.put(NS.as_ref(), ns.to_owned().into());
 		ctx.add_value("session", session);
 		opt.set_ns(Some(ns.into()));
+		self.guard(opt).await;
 	}
 
 	async fn set_db(&self, ctx: &mut Context<'_>, opt: &mut Options, db: &str) {
@@ -158,6 +244,15 @@ impl<'a> Executor<'a> {
 		session.put(DB.as_ref(), db.to_owned().into());
 		ctx.add_value("session", session);
 		opt.set_db(Some(db.into()));
+		self.guard(opt).await;
+	}
+
+	/// Restrict any open transaction to the currently selected namespace
+	/// and database, for sessions which are guarded by the selection.
+	async fn guard(&self, opt: &Options) {
+		if let Some(txn) = self.txn.as_ref() {
+			txn.lock().await.guard(&opt.auth, opt.selected_ns(), opt.selected_db());
+		}
 	}
 
 	#[instrument(name = "executor", skip_all)]
@@ -175,12 +270,20 @@ impl<'a> Executor<'a> {
 		let mut buf: Vec<Response> = vec![];
 		// Initialise array of responses
 		let mut out: Vec<Response> = vec![];
+		// Run a dry run, or an atomic query, within a single transaction
+		if self.dr || self.tx {
+			if let Err(e) = self.begin(true, &opt).await {
+				self.aborted = true;
+				self.reason = Some(e);
+			}
+			ctx.add_explicit(true);
+		}
 		// Process all statements in query
 		for stm in qry.into_iter() {
 			// Log the statement
 			debug!("Executing: {}", stm);
 			// Reset errors
-			if self.txn.is_none() {
+			if self.txn.is_none() && !self.aborted {
 				self.err = false;
 			}
 			// Get the statement start time
@@ -191,6 +294,11 @@ impl<'a> Executor<'a> {
 			let is_stm_kill = matches!(stm, Statement::Kill(_));
 			// Check if this is a RETURN statement
 			let is_stm_output = matches!(stm, Statement::Output(_));
+			// Collect any warnings for this statement
+			let warnings = Arc::new(std::sync::Mutex::new(Vec::new()));
+			// Count the records read and written by this statement
+			let usage = Arc::new(Counter::default());
+			ctx.add_usage(&usage);
 			// Process a single statement
 			let res = match stm {
 				// Specify runtime options
@@ -213,29 +321,46 @@ impl<'a> Executor<'a> {
 					// Continue
 					continue;
 				}
+				// A dry run, or an atomic query, already runs in a transaction
+				Statement::Begin(_) | Statement::Cancel(_) | Statement::Commit(_)
+					if self.dr || self.tx =>
+				{
+					Err(Error::TxStatementNotAllowed)
+				}
 				// Begin a new transaction
 				Statement::Begin(_) => {
-					self.begin(true).await;
+					// Fail the statements of the transaction if it couldn't begin
+					if let Err(e) = self.begin(true, &opt).await {
+						self.aborted = true;
+						self.reason = Some(e);
+					}
+					ctx.add_explicit(true);
 					continue;
 				}
 				// Cancel a running transaction
 				Statement::Cancel(_) => {
+					self.aborted = false;
+					self.reason = None;
 					self.cancel(true).await;
 					self.clear(&ctx, recv.clone()).await;
 					buf = buf.into_iter().map(|v| self.buf_cancel(v)).collect();
 					out.append(&mut buf);
 					debug_assert!(self.txn.is_none(), "cancel(true) should have unset txn");
 					self.txn = None;
+					ctx.add_explicit(false);
 					continue;
 				}
 				// Commit a running transaction
 				Statement::Commit(_) => {
+					self.aborted = false;
+					self.reason = None;
 					let commit_error = self.commit(true).await.err();
 					buf = buf.into_iter().map(|v| self.buf_commit(v, &commit_error)).collect();
 					self.flush(&ctx, recv.clone()).await;
 					out.append(&mut buf);
 					debug_assert!(self.txn.is_none(), "commit(true) should have unset txn");
 					self.txn = None;
+					ctx.add_explicit(false);
 					continue;
 				}
 				// Switch to a different NS or DB
@@ -273,13 +398,18 @@ impl<'a> Executor<'a> {
 				// Process param definition statements
 				Statement::Set(mut stm) => {
 					// Create a transaction
-					let loc = self.begin(stm.writeable()).await;
+					let loc = match self.err {
+						// This transaction has failed
+						true => Err(self.reason.take().unwrap_or(Error::TxFailure)),
+						// Create a transaction if needed
+						false => self.begin(stm.writeable(), &opt).await,
+					};
 					// Check the transaction
-					match self.err {
+					match loc {
 						// We failed to create a transaction
-						true => Err(Error::TxFailure),
+						Err(e) => Err(e),
 						// The transaction began successfully
-						false => {
+						Ok(loc) => {
 							// Check if the variable is a protected variable
 							let res = match PROTECTED_PARAM_NAMES.contains(&stm.name.as_str()) {
 								// The variable isn't protected and can be stored
@@ -332,62 +462,86 @@ impl<'a> Executor<'a> {
 				// Process all other normal statements
 				_ => match self.err {
 					// This transaction has failed
-					true => Err(Error::QueryNotExecuted),
+					true => Err(self.reason.take().unwrap_or(Error::QueryNotExecuted)),
 					// Compute the statement normally
 					false => {
-						// Create a transaction
-						let loc = self.begin(stm.writeable()).await;
-						// Check the transaction
-						match self.err {
-							// We failed to create a transaction
-							true => Err(Error::TxFailure),
+						// The number of times this statement has been retried
+						let mut retries = 0;
+						loop {
+							// Create a transaction
+							let loc = match self.begin(stm.writeable(), &opt).await {
+								Ok(loc) => loc,
+								// We failed to create a transaction
+								Err(e) => break Err(e),
+							};
 							// The transaction began successfully
-							false => {
-								let mut ctx = Context::new(&ctx);
-								// Process the statement
-								let res = match stm.timeout() {
-									// There is a timeout clause
-									Some(timeout) => {
-										// Set statement timeout
-										ctx.add_timeout(timeout);
-										// Process the statement
-										let res = stm.compute(&ctx, &opt, &self.txn(), None).await;
-										// Catch statement timeout
-										match ctx.is_timedout() {
-											true => Err(Error::QueryTimedout),
-											false => res,
-										}
+							let mut ctx = Context::new(&ctx);
+							// Count the records of this attempt only
+							usage.reset();
+							// Collect the warnings for this attempt only
+							if self.wn {
+								warnings.lock().unwrap().clear();
+								ctx.add_warnings(&warnings);
+							}
+							// Track any external effects of this attempt
+							let effects = Arc::new(AtomicBool::new(false));
+							ctx.add_effects(&effects);
+							// Process the statement
+							let res = match stm.timeout() {
+								// There is a timeout clause
+								Some(timeout) => {
+									// Set statement timeout
+									ctx.add_timeout(timeout);
+									// Process the statement
+									let res = stm.compute(&ctx, &opt, &self.txn(), None).await;
+									// Catch statement timeout
+									match ctx.is_timedout() {
+										true => Err(Error::QueryTimedout),
+										false => res,
+									}
+								}
+								// There is no timeout clause
+								None => stm.compute(&ctx, &opt, &self.txn(), None).await,
+							};
+							// Catch global timeout
+							let res = match ctx.is_timedout() {
+								true => Err(Error::QueryTimedout),
+								false => res,
+							};
+							// Finalise transaction and return the result.
+							if res.is_ok() && stm.writeable() {
+								match self.commit(loc).await {
+									Ok(_) => {
+										// Flush the live query change notifications
+										self.flush(&ctx, recv.clone()).await;
+										// Successful, committed result
+										break res;
 									}
-									// There is no timeout clause
-									None => stm.compute(&ctx, &opt, &self.txn(), None).await,
-								};
-								// Catch global timeout
-								let res = match ctx.is_timedout() {
-									true => Err(Error::QueryTimedout),
-									false => res,
-								};
-								// Finalise transaction and return the result.
-								if res.is_ok() && stm.writeable() {
-									if let Err(e) = self.commit(loc).await {
+									Err(e) => {
 										// Clear live query notification details
 										self.clear(&ctx, recv.clone()).await;
+										// Retry if the transaction conflicted
+										if self.retry(loc, &e, &effects, &mut retries).await {
+											continue;
+										}
 										// The commit failed
-										Err(Error::QueryNotExecutedDetail {
+										break Err(Error::QueryNotExecutedDetail {
 											message: e.to_string(),
-										})
-									} else {
-										// Flush the live query change notifications
-										self.flush(&ctx, recv.clone()).await;
-										// Successful, committed result
-										res
+										});
 									}
-								} else {
-									self.cancel(loc).await;
-									// Clear live query notification details
-									self.clear(&ctx, recv.clone()).await;
-									// Return an error
-									res
 								}
+							} else {
+								self.cancel(loc).await;
+								// Clear live query notification details
+								self.clear(&ctx, recv.clone()).await;
+								// Retry if the statement conflicted
+								if let Err(e) = &res {
+									if self.retry(loc, e, &effects, &mut retries).await {
+										continue;
+									}
+								}
+								// Return the result
+								break res;
 							}
 						}
 					}
@@ -403,15 +557,28 @@ impl<'a> Executor<'a> {
 					self.err = true;
 					e
 				}),
+				// Get the statement warnings
+				warnings: std::mem::take(&mut *warnings.lock().unwrap()),
+				// Get the records read and written by the statement
+				stats: self.st.then(|| Stats {
+					reads: usage.reads(),
+					writes: usage.writes(),
+					indexes: usage.indexes(),
+				}),
 				query_type: match (is_stm_live, is_stm_kill) {
 					(true, _) => QueryType::Live,
 					(_, true) => QueryType::Kill,
 					_ => QueryType::Other,
 				},
 			};
+			// Account for the resources used in the namespace, unless this is a dry run
+			if let (Some(ns), false) = (opt.selected_ns(), self.dr) {
+				self.kvs.add_usage(ns, res.time, &usage);
+			}
 			// Output the response
 			if self.txn.is_some() {
-				if is_stm_output {
+				// Only a RETURN in an explicit transaction replaces its results
+				if is_stm_output && !(self.dr || self.tx) {
 					buf.clear();
 				}
 				buf.push(res);
@@ -419,6 +586,15 @@ impl<'a> Executor<'a> {
 				out.push(res)
 			}
 		}
+		// Commit the transaction which all of the statements ran in
+		if (self.dr || self.tx) && self.txn.is_some() {
+			self.aborted = false;
+			self.reason = None;
+			let commit_error = self.commit(true).await.err();
+			buf = buf.into_iter().map(|v| self.buf_commit(v, &commit_error)).collect();
+			self.flush(&ctx, recv.clone()).await;
+			out.append(&mut buf);
+		}
 		// Return responses
 		Ok(out)
 	}
@@ -0,0 +1,65 @@
+use crate::err::Error;
+use crate::sql::value::Value;
+use crate::sql::Kind;
+use std::panic::{catch_unwind, AssertUnwindSafe};
+
+/// The callback which implements a [`CustomFunction`].
+pub type Callback = dyn Fn(Vec<Value>) -> Result<Value, String> + Send + Sync;
+
+/// A function implemented by an embedding application, which can
+/// be called from queries in the same way as `fn::name()` functions
+/// created with a `DEFINE FUNCTION` statement.
+///
+/// The arguments are checked against the expected kinds before the
+/// callback is run, and any panic within the callback is caught and
+/// returned as an error, so that it does not bring down the datastore
+/// (unless the application is built with `panic = "abort"`).
+pub struct CustomFunction {
+	args: Vec<Kind>,
+	run: Box<Callback>,
+}
+
+impl CustomFunction {
+	/// Create a new function which accepts arguments of the specified kinds.
+	pub fn new<F>(args: Vec<Kind>, run: F) -> Self
+	where
+		F: Fn(Vec<Value>) -> Result<Value, String> + Send + Sync + 'static,
+	{
+		CustomFunction {
+			args,
+			run: Box::new(run),
+		}
+	}
+
+	/// Check the arguments and run the function.
+	pub(crate) fn run(&self, name: &str, args: Vec<Value>) -> Result<Value, Error> {
+		// Check the function arguments
+		if args.len() != self.args.len() {
+			return Err(Error::InvalidArguments {
+				name: format!("fn::{name}"),
+				message: match self.args.len() {
+					1 => String::from("The function expects 1 argument."),
+					l => format!("The function expects {l} arguments."),
+				},
+			});
+		}
+		// Process the function arguments
+		let args = args
+			.into_iter()
+			.zip(self.args.iter())
+			.map(|(val, kind)| val.coerce_to(kind))
+			.collect::<Result<Vec<_>, _>>()?;
+		// Run the function, catching any panics
+		match catch_unwind(AssertUnwindSafe(|| (self.run)(args))) {
+			Ok(Ok(v)) => Ok(v),
+			Ok(Err(message)) => Err(Error::InvalidFunction {
+				name: format!("fn::{name}"),
+				message,
+			}),
+			Err(_) => Err(Error::InvalidFunction {
+				name: format!("fn::{name}"),
+				message: String::from("The function panicked."),
+			}),
+		}
+	}
+}
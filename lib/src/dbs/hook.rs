@@ -0,0 +1,24 @@
+use crate::sql::thing::Thing;
+use crate::sql::value::Value;
+
+/// Callbacks which are run whenever a record is written by the datastore.
+///
+/// Hooks run within the transaction of the statement which wrote the record,
+/// after the record has been stored and after any table events have run. If a
+/// hook returns an error, then the statement fails and the write is discarded
+/// along with the rest of the transaction, allowing embedding applications to
+/// validate or mirror changes without defining events in SQL.
+pub trait Hook: Send + Sync {
+	/// Called after a record has been created.
+	fn on_create(&self, _rid: &Thing, _after: &Value) -> Result<(), String> {
+		Ok(())
+	}
+	/// Called after a record has been updated.
+	fn on_update(&self, _rid: &Thing, _before: &Value, _after: &Value) -> Result<(), String> {
+		Ok(())
+	}
+	/// Called after a record has been deleted.
+	fn on_delete(&self, _rid: &Thing, _before: &Value) -> Result<(), String> {
+		Ok(())
+	}
+}
@@ -11,7 +11,9 @@ use crate::idx::planner::plan::Plan;
 use crate::sql::array::Array;
 use crate::sql::edges::Edges;
 use crate::sql::field::Field;
+use crate::sql::order::Order;
 use crate::sql::range::Range;
+use crate::sql::start::Position;
 use crate::sql::table::Table;
 use crate::sql::thing::Thing;
 use crate::sql::value::Value;
@@ -19,7 +21,10 @@ use async_recursion::async_recursion;
 use std::borrow::Cow;
 use std::cmp::Ordering;
 use std::collections::BTreeMap;
+use std::collections::HashSet;
+use std::iter;
 use std::mem;
+use std::ops::Bound;
 
 pub(crate) enum Iterable {
 	Value(Value),
@@ -52,10 +57,14 @@ pub(crate) struct Iterator {
 	limit: Option<usize>,
 	// Iterator start value
 	start: Option<usize>,
+	// Iterator resume cursor
+	after: Option<(Vec<Value>, Thing)>,
 	// Iterator runtime error
 	error: Option<Error>,
 	// Iterator output results
 	results: Vec<Value>,
+	// Iterator record ids for ordering
+	ids: Vec<Option<Thing>>,
 	// Iterator input values
 	entries: Vec<Iterable>,
 }
@@ -88,6 +97,8 @@ impl Iterator {
 		self.setup_limit(&cancel_ctx, opt, txn, stm).await?;
 		// Process the query START clause
 		self.setup_start(&cancel_ctx, opt, txn, stm).await?;
+		// Only scan the records after the START AFTER cursor
+		self.setup_after(stm);
 
 		// Extract the expected behaviour depending on the presence of EXPLAIN with or without FULL
 		let (do_iterate, mut explanation) = Explanation::new(stm.explain(), &self.entries);
@@ -103,6 +114,8 @@ impl Iterator {
 			self.output_split(ctx, opt, txn, stm).await?;
 			// Process any GROUP clause
 			self.output_group(ctx, opt, txn, stm).await?;
+			// Process any DISTINCT clause
+			self.output_distinct(ctx, opt, txn, stm).await?;
 			// Process any ORDER clause
 			self.output_order(ctx, opt, txn, stm).await?;
 			// Process any START clause
@@ -151,11 +164,65 @@ impl Iterator {
 		stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		if let Some(v) = stm.start() {
-			self.start = Some(v.process(ctx, opt, txn, None).await?);
+			match v.process(ctx, opt, txn, None).await? {
+				Position::Offset(v) => self.start = Some(v),
+				Position::After(v, id) => self.after = Some((v, id)),
+			}
 		}
 		Ok(())
 	}
 
+	#[inline]
+	fn setup_after(&mut self, stm: &Statement<'_>) {
+		// Only results ordered by id can skip the records before the cursor
+		if stm.order().is_some() || stm.group().is_some() {
+			return;
+		}
+		let id = match &self.after {
+			Some((v, id)) if v.is_empty() => id.clone(),
+			_ => return,
+		};
+		// Scan the tables and ranges from the cursor onwards
+		let mut entries = Vec::with_capacity(self.entries.len());
+		for v in mem::take(&mut self.entries) {
+			match v {
+				// The whole table is before the cursor
+				Iterable::Table(t) if t.0 < id.tb => continue,
+				// Start the table scan after the cursor
+				Iterable::Table(t) if t.0 == id.tb => entries.push(Iterable::Range(Range {
+					tb: t.0,
+					beg: Bound::Excluded(id.id.clone()),
+					end: Bound::Unbounded,
+				})),
+				// The whole range is before the cursor
+				Iterable::Range(r) if r.tb < id.tb => continue,
+				// Start the range scan after the cursor
+				Iterable::Range(mut r) if r.tb == id.tb => {
+					if matches!(&r.end, Bound::Included(e) | Bound::Excluded(e) if &id.id >= e) {
+						continue;
+					}
+					if match &r.beg {
+						Bound::Included(b) | Bound::Excluded(b) => &id.id >= b,
+						Bound::Unbounded => true,
+					} {
+						r.beg = Bound::Excluded(id.id.clone());
+					}
+					entries.push(Iterable::Range(r));
+				}
+				v => entries.push(v),
+			}
+		}
+		self.entries = entries;
+		// A single table or range is scanned in id order, so the
+		// results already start after the cursor, and the scan
+		// can stop as soon as the LIMIT is reached
+		if self.entries.len() <= 1
+			&& self.entries.iter().all(|v| matches!(v, Iterable::Table(_) | Iterable::Range(_)))
+		{
+			self.after = None;
+		}
+	}
+
 	#[inline]
 	async fn output_split(
 		&mut self,
@@ -169,8 +236,10 @@ impl Iterator {
 			for split in splits.iter() {
 				// Get the query result
 				let res = mem::take(&mut self.results);
+				// Get the record ids
+				let ids = mem::take(&mut self.ids);
 				// Loop over each value
-				for obj in &res {
+				for (i, obj) in res.iter().enumerate() {
 					// Get the value at the path
 					let val = obj.pick(split);
 					// Set the value at the path
@@ -183,6 +252,10 @@ impl Iterator {
 								obj.set(ctx, opt, txn, split, val).await?;
 								// Add the object to the results
 								self.results.push(obj);
+								// Keep the record id with the object
+								if let Some(id) = ids.get(i) {
+									self.ids.push(id.clone());
+								}
 							}
 						}
 						_ => {
@@ -192,6 +265,10 @@ impl Iterator {
 							obj.set(ctx, opt, txn, split, val).await?;
 							// Add the object to the results
 							self.results.push(obj);
+							// Keep the record id with the object
+							if let Some(id) = ids.get(i) {
+								self.ids.push(id.clone());
+							}
 						}
 					}
 				}
@@ -214,6 +291,8 @@ impl Iterator {
 				let mut grp: BTreeMap<Array, Array> = BTreeMap::new();
 				// Get the query result
 				let res = mem::take(&mut self.results);
+				// Grouped results have no record ids
+				self.ids.clear();
 				// Loop over each value
 				for obj in res {
 					// Create a new column set
@@ -281,67 +360,114 @@ impl Iterator {
 	}
 
 	#[inline]
-	async fn output_order(
+	async fn output_distinct(
 		&mut self,
 		_ctx: &Context<'_>,
 		_opt: &Options,
 		_txn: &Transaction,
 		stm: &Statement<'_>,
 	) -> Result<(), Error> {
-		if let Some(orders) = stm.order() {
-			// Sort the full result set
-			self.results.sort_by(|a, b| {
-				// Loop over each order clause
-				for order in orders.iter() {
-					// Reverse the ordering if DESC
-					let o = match order.random {
-						true => {
-							let a = rand::random::<f64>();
-							let b = rand::random::<f64>();
-							a.partial_cmp(&b)
-						}
-						false => match order.direction {
-							true => a.compare(b, order, order.collate, order.numeric),
-							false => b.compare(a, order, order.collate, order.numeric),
-						},
-					};
-					//
-					match o {
-						Some(Ordering::Greater) => return Ordering::Greater,
-						Some(Ordering::Equal) => continue,
-						Some(Ordering::Less) => return Ordering::Less,
-						None => continue,
+		if stm.distinct() {
+			// Keep the first of each set of duplicate results
+			let mut seen = HashSet::with_capacity(self.results.len());
+			let mut ids = mem::take(&mut self.ids).into_iter();
+			for v in mem::take(&mut self.results) {
+				let id = ids.next();
+				if seen.insert(v.clone()) {
+					self.results.push(v);
+					// Keep the record ids in step with the results
+					if let Some(id) = id {
+						self.ids.push(id);
 					}
 				}
-				Ordering::Equal
-			})
+			}
 		}
 		Ok(())
 	}
 
 	#[inline]
-	async fn output_start(
+	async fn output_order(
 		&mut self,
 		_ctx: &Context<'_>,
 		_opt: &Options,
 		_txn: &Transaction,
-		_stm: &Statement<'_>,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Results are ordered by id when resuming
+		if stm.order().is_some() || self.after.is_some() {
+			// Get the order clauses
+			let orders = stm.order().map(|o| o.as_slice()).unwrap_or_default();
+			// Pair each result with its record id
+			let ids = mem::take(&mut self.ids).into_iter().chain(iter::repeat(None));
+			let mut res: Vec<_> = ids.zip(mem::take(&mut self.results)).collect();
+			// Sort the full result set
+			res.sort_by(|(x, a), (y, b)| compare(orders, (x, a), (y, b)));
+			// Store the sorted result set
+			(self.ids, self.results) = res.into_iter().unzip();
+		}
+		Ok(())
+	}
+
+	#[inline]
+	async fn output_start(
+		&mut self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		if let Some(v) = self.start {
 			self.results = mem::take(&mut self.results).into_iter().skip(v).collect();
 		}
+		if let Some((vals, id)) = self.after.take() {
+			// Get the order clauses
+			let orders = stm.order().map(|o| o.as_slice()).unwrap_or_default();
+			// The cursor must hold a value for each order clause
+			if stm.group().is_some()
+				|| vals.len() != orders.len()
+				|| orders.iter().any(|o| o.random)
+			{
+				return Err(Error::InvalidCursor {
+					value: match vals.is_empty() {
+						true => id.to_string(),
+						false => Value::from([vals, vec![id.into()]].concat()).to_string(),
+					},
+				});
+			}
+			// Set the ordered values of the cursor
+			let mut cur = Value::base();
+			for (order, val) in orders.iter().zip(vals) {
+				cur.set(ctx, opt, txn, order, val).await?;
+			}
+			let id = Some(id);
+			// Skip the results which are sorted up to the cursor,
+			// whether or not its record still exists
+			let ids = mem::take(&mut self.ids);
+			let pos = ids
+				.iter()
+				.zip(self.results.iter())
+				.take_while(|(x, a)| compare(orders, (*x, *a), (&id, &cur)) != Ordering::Greater)
+				.count();
+			self.results = mem::take(&mut self.results).into_iter().skip(pos).collect();
+		}
 		Ok(())
 	}
 
 	#[inline]
 	async fn output_limit(
 		&mut self,
-		_ctx: &Context<'_>,
+		ctx: &Context<'_>,
 		_opt: &Options,
 		_txn: &Transaction,
-		_stm: &Statement<'_>,
+		stm: &Statement<'_>,
 	) -> Result<(), Error> {
 		if let Some(v) = self.limit {
+			// Let the user know that more records matched
+			if matches!(stm, Statement::Select(_)) && ctx.has_warnings() && self.results.len() > v {
+				ctx.add_warning(format!(
+					"More records matched than the LIMIT of {v}, so the results were truncated"
+				));
+			}
 			self.results = mem::take(&mut self.results).into_iter().take(v).collect();
 		}
 		Ok(())
@@ -445,8 +571,8 @@ impl Iterator {
 				// Create an async closure to process results
 				let aproc = async {
 					// Process all processed values
-					while let Ok(r) = vals.recv().await {
-						self.result(r, stm);
+					while let Ok((k, r)) = vals.recv().await {
+						self.result(ctx, k, r, stm);
 					}
 					// Shutdown the executor
 					let _ = end.send(()).await;
@@ -497,12 +623,20 @@ impl Iterator {
 			Statement::Insert(_) => doc.insert(ctx, opt, txn, stm).await,
 			_ => unreachable!(),
 		};
+		// Drop the document
+		drop(doc);
 		// Process the result
-		self.result(res, stm);
+		self.result(ctx, thg, res, stm);
 	}
 
 	/// Accept a processed record result
-	fn result(&mut self, res: Result<Value, Error>, stm: &Statement<'_>) {
+	fn result(
+		&mut self,
+		ctx: &Context<'_>,
+		thg: Option<Thing>,
+		res: Result<Value, Error>,
+		stm: &Statement<'_>,
+	) {
 		// Process the result
 		match res {
 			Err(Error::Ignore) => {
@@ -515,9 +649,21 @@ impl Iterator {
 			}
 			Ok(v) => self.results.push(v),
 		}
+		// Keep the record id for ordering
+		if stm.order().is_some() || self.after.is_some() {
+			self.ids.push(thg);
+		}
 		// Check if we can exit
-		if stm.group().is_none() && stm.order().is_none() {
+		if stm.group().is_none() && stm.order().is_none() && !stm.distinct() && self.after.is_none()
+		{
 			if let Some(l) = self.limit {
+				// Select one more record to know if the results are truncated.
+				// This costs an extra record read, so is only done when the
+				// warnings are reported.
+				let l = match stm {
+					Statement::Select(_) if ctx.has_warnings() => l + 1,
+					_ => l,
+				};
 				if let Some(s) = self.start {
 					if self.results.len() == l + s {
 						self.run.cancel()
@@ -529,3 +675,39 @@ impl Iterator {
 		}
 	}
 }
+
+/// Compares two results using the ORDER clauses, and breaks
+/// any ties using the record id, so that pages are stable
+fn compare(
+	orders: &[Order],
+	(x, a): (&Option<Thing>, &Value),
+	(y, b): (&Option<Thing>, &Value),
+) -> Ordering {
+	// Loop over each order clause
+	for order in orders.iter() {
+		// Reverse the ordering if DESC
+		let o = match order.random {
+			true => {
+				let a = rand::random::<f64>();
+				let b = rand::random::<f64>();
+				a.partial_cmp(&b)
+			}
+			false => match order.direction {
+				true => a.compare(b, order, order.collate, order.numeric),
+				false => b.compare(a, order, order.collate, order.numeric),
+			},
+		};
+		//
+		match o {
+			Some(Ordering::Greater) => return Ordering::Greater,
+			Some(Ordering::Equal) => continue,
+			Some(Ordering::Less) => return Ordering::Less,
+			None => continue,
+		}
+	}
+	// Results in a random order are not tied
+	match orders.iter().any(|o| o.random) {
+		true => Ordering::Equal,
+		false => x.cmp(y),
+	}
+}
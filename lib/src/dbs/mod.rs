@@ -3,8 +3,11 @@
 //! glue between the API and the response. In this module we use channels as a transport layer
 //! and executors to process the operations. This module also gives a `context` to the transaction.
 mod auth;
+mod capabilities;
 mod executor;
 mod explanation;
+mod function;
+mod hook;
 mod iterator;
 mod notification;
 mod options;
@@ -12,18 +15,24 @@ mod response;
 mod session;
 mod statement;
 mod transaction;
+mod usage;
 mod variables;
 
 pub use self::auth::*;
+pub use self::capabilities::*;
+pub use self::function::*;
+pub use self::hook::*;
 pub use self::notification::*;
 pub use self::options::*;
 pub use self::response::*;
 pub use self::session::*;
+pub use self::usage::Usage;
 
 pub(crate) use self::executor::*;
 pub(crate) use self::iterator::*;
 pub(crate) use self::statement::*;
 pub(crate) use self::transaction::*;
+pub(crate) use self::usage::{Accounting, Counter};
 pub(crate) use self::variables::*;
 
 pub mod cl;
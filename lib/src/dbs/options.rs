@@ -345,6 +345,16 @@ impl Options {
 		// self.db.as_ref().map(AsRef::as_ref).ok_or(Error::Unreachable)
 	}
 
+	/// Get currently selected NS, if one is selected
+	pub(crate) fn selected_ns(&self) -> Option<&str> {
+		self.ns.as_deref()
+	}
+
+	/// Get currently selected DB, if one is selected
+	pub(crate) fn selected_db(&self) -> Option<&str> {
+		self.db.as_deref()
+	}
+
 	/// Check whether this request supports realtime queries
 	pub fn realtime(&self) -> Result<(), Error> {
 		if !self.live {
@@ -53,6 +53,10 @@ impl<'a> Processor<'a> {
 		doc_id: Option<DocId>,
 		val: Operable,
 	) -> Result<(), Error> {
+		// Count the records read from storage
+		if rid.is_some() {
+			ctx.add_read();
+		}
 		match self {
 			Processor::Iterator(ite) => {
 				ite.process(ctx, opt, txn, stm, rid, doc_id, val).await;
@@ -236,7 +240,7 @@ impl<'a> Processor<'a> {
 						nxt = Some(k.clone());
 					}
 					// Parse the data from the store
-					let key: crate::key::thing::Thing = (&k).into();
+					let key = crate::key::thing::Thing::decode(&k)?;
 					let val: crate::sql::value::Value = (&v).into();
 					let rid = Thing::from((key.tb, key.id));
 					// Create a new operable value
@@ -319,7 +323,7 @@ impl<'a> Processor<'a> {
 						nxt = Some(k.clone());
 					}
 					// Parse the data from the store
-					let key: crate::key::thing::Thing = (&k).into();
+					let key = crate::key::thing::Thing::decode(&k)?;
 					let val: crate::sql::value::Value = (&v).into();
 					let rid = Thing::from((key.tb, key.id));
 					// Create a new operable value
@@ -454,7 +458,7 @@ impl<'a> Processor<'a> {
 							nxt = Some(k.clone());
 						}
 						// Parse the data from the store
-						let gra: crate::key::graph::Graph = (&k).into();
+						let gra = crate::key::graph::Graph::decode(&k)?;
 						// Fetch the data from the store
 						let key = thing::new(opt.ns(), opt.db(), gra.ft, &gra.fk);
 						let val = txn.lock().await.get(key).await?;
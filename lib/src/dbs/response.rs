@@ -22,10 +22,26 @@ pub enum QueryType {
 pub struct Response {
 	pub time: Duration,
 	pub result: Result<Value, Error>,
+	/// Any non-fatal problems which were found when running the query
+	pub warnings: Vec<String>,
+	/// The records read and written by the statement, if they were requested
+	pub stats: Option<Stats>,
 	// Record the query type in case processing the response is necessary (such as tracking live queries).
 	pub query_type: QueryType,
 }
 
+/// The records which a statement read and wrote, and the indexes it used
+#[derive(Clone, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct Stats {
+	/// The number of records which were read from the storage engine
+	pub reads: u64,
+	/// The number of records which were written or deleted
+	pub writes: u64,
+	/// The names of the indexes which were used to find the records
+	pub indexes: Vec<String>,
+}
+
 impl Response {
 	/// Return the transaction duration as a string
 	pub fn speed(&self) -> String {
@@ -1,5 +1,6 @@
 use crate::ctx::Context;
 use crate::dbs::Auth;
+use crate::kvs::Priority;
 use crate::sql::value::Value;
 use std::sync::Arc;
 
@@ -26,6 +27,16 @@ pub struct Session {
 	pub tk: Option<Value>,
 	/// The current scope authentication data
 	pub sd: Option<Value>,
+	/// The priority of write transactions when the datastore is overloaded
+	pub pr: Priority,
+	/// Whether queries are validated without committing their changes
+	pub dr: bool,
+	/// Whether all of the statements of a query run in a single transaction
+	pub tx: bool,
+	/// Whether the statements of a query report non-fatal warnings
+	pub wn: bool,
+	/// Whether the statements of a query report the records they read and wrote
+	pub st: bool,
 }
 
 impl Session {
@@ -82,6 +93,37 @@ impl Session {
 		self.db = Some(db.to_owned());
 		self
 	}
+	/// Set the priority of write transactions for the session
+	pub fn with_priority(mut self, pr: Priority) -> Session {
+		self.pr = pr;
+		self
+	}
+	/// Set whether queries are validated without committing their changes
+	///
+	/// A dry run computes every statement in full, including its writes, in a
+	/// single transaction which is cancelled at the end. It does not return
+	/// query plans. Remote HTTP requests and datastore hooks are not run, and
+	/// sequence values, namespace usage and index suggestions are left as they
+	/// were.
+	pub fn with_dry_run(mut self, dr: bool) -> Session {
+		self.dr = dr;
+		self
+	}
+	/// Set whether all of the statements of a query run in a single transaction
+	pub fn with_transaction(mut self, tx: bool) -> Session {
+		self.tx = tx;
+		self
+	}
+	/// Set whether the statements of a query report non-fatal warnings
+	pub fn with_warnings(mut self, wn: bool) -> Session {
+		self.wn = wn;
+		self
+	}
+	/// Set whether the statements of a query report the records they read and wrote
+	pub fn with_stats(mut self, st: bool) -> Session {
+		self.st = st;
+		self
+	}
 	/// Retrieves the selected namespace
 	pub(crate) fn ns(&self) -> Option<Arc<str>> {
 		self.ns.as_deref().map(Into::into)
@@ -160,6 +160,14 @@ impl<'a> Statement<'a> {
 			_ => None,
 		}
 	}
+	/// Returns whether duplicate results are removed
+	#[inline]
+	pub fn distinct(&self) -> bool {
+		match self {
+			Statement::Select(v) => v.distinct,
+			_ => false,
+		}
+	}
 	/// Returns any FETCH clause if specified
 	#[inline]
 	pub fn fetch(&self) -> Option<&Fetchs> {
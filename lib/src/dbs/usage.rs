@@ -0,0 +1,118 @@
+use std::collections::BTreeMap;
+use std::sync::atomic::{AtomicU64, Ordering};
+use std::sync::Mutex;
+use std::time::Duration;
+
+/// The resources which have been used by the statements run in a namespace
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct Usage {
+	/// The time spent processing statements
+	pub time: Duration,
+	/// The number of records which were read from the storage engine
+	pub reads: u64,
+	/// The number of records which were written or deleted
+	pub writes: u64,
+	/// The number of bytes of record data which were written
+	pub bytes: u64,
+}
+
+/// Counts the records which are read and written by a single statement
+#[derive(Debug, Default)]
+pub(crate) struct Counter {
+	reads: AtomicU64,
+	writes: AtomicU64,
+	bytes: AtomicU64,
+	indexes: Mutex<Vec<String>>,
+}
+
+impl Counter {
+	/// Count a record which was read from the storage engine
+	pub fn read(&self) {
+		self.reads.fetch_add(1, Ordering::Relaxed);
+	}
+	/// Count a record of the specified size which was written
+	pub fn write(&self, bytes: usize) {
+		self.writes.fetch_add(1, Ordering::Relaxed);
+		self.bytes.fetch_add(bytes as u64, Ordering::Relaxed);
+	}
+	/// The number of records which were read from the storage engine
+	pub fn reads(&self) -> u64 {
+		self.reads.load(Ordering::Relaxed)
+	}
+	/// The number of records which were written or deleted
+	pub fn writes(&self) -> u64 {
+		self.writes.load(Ordering::Relaxed)
+	}
+	/// Note an index which was used to find records
+	pub fn index(&self, name: &str) {
+		let mut indexes = self.indexes.lock().unwrap_or_else(|e| e.into_inner());
+		if !indexes.iter().any(|v| v == name) {
+			indexes.push(name.to_owned());
+		}
+	}
+	/// The names of the indexes which were used to find records
+	pub fn indexes(&self) -> Vec<String> {
+		self.indexes.lock().unwrap_or_else(|e| e.into_inner()).clone()
+	}
+	/// Forget everything which has been counted so far
+	pub fn reset(&self) {
+		self.reads.store(0, Ordering::Relaxed);
+		self.writes.store(0, Ordering::Relaxed);
+		self.bytes.store(0, Ordering::Relaxed);
+		self.indexes.lock().unwrap_or_else(|e| e.into_inner()).clear();
+	}
+}
+
+/// The resources used by each namespace since the datastore was started
+#[derive(Debug, Default)]
+pub(crate) struct Accounting(Mutex<BTreeMap<String, Usage>>);
+
+impl Accounting {
+	/// Add the resources used by a statement to a namespace
+	pub fn add(&self, ns: &str, time: Duration, counter: &Counter) {
+		let mut namespaces = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		let usage = namespaces.entry(ns.to_owned()).or_default();
+		usage.time += time;
+		usage.reads += counter.reads.load(Ordering::Relaxed);
+		usage.writes += counter.writes.load(Ordering::Relaxed);
+		usage.bytes += counter.bytes.load(Ordering::Relaxed);
+	}
+	/// Get the resources used by a namespace
+	pub fn get(&self, ns: &str) -> Usage {
+		let namespaces = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		namespaces.get(ns).copied().unwrap_or_default()
+	}
+	/// Get the resources used by every namespace, in order of name
+	pub fn all(&self) -> Vec<(String, Usage)> {
+		let namespaces = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		namespaces.iter().map(|(k, v)| (k.clone(), *v)).collect()
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn accounting() {
+		let acc = Accounting::default();
+		let cnt = Counter::default();
+		cnt.read();
+		cnt.read();
+		cnt.write(10);
+		acc.add("test", Duration::from_millis(5), &cnt);
+		acc.add("test", Duration::from_millis(5), &cnt);
+		acc.add("other", Duration::ZERO, &Counter::default());
+		let usage = acc.get("test");
+		assert_eq!(usage.time, Duration::from_millis(10));
+		assert_eq!(usage.reads, 4);
+		assert_eq!(usage.writes, 2);
+		assert_eq!(usage.bytes, 20);
+		assert_eq!(acc.get("none"), Usage::default());
+		let all = acc.all();
+		assert_eq!(all.len(), 2);
+		assert_eq!(all[0].0, "other");
+		assert_eq!(all[1].0, "test");
+	}
+}
@@ -0,0 +1,49 @@
+use crate::cf::mutations::TableMutation;
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::dbs::Transaction;
+use crate::doc::Document;
+use crate::err::Error;
+use crate::sql::idiom::Idiom;
+
+impl<'a> Document<'a> {
+	pub async fn changefeeds(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Check if forced
+		if !opt.force && !self.changed() {
+			return Ok(());
+		}
+		// Get the table for the record
+		let tb = self.tb(opt, txn).await?;
+		// Claim transaction
+		let mut run = txn.lock().await;
+		// Get the database for the record
+		let db = run.get_and_cache_db(opt.ns(), opt.db()).await?;
+		// Check if changefeeds are enabled
+		if db.changefeed.is_none() && tb.changefeed.is_none() {
+			return Ok(());
+		}
+		// Get the record id
+		let rid = self.id.as_ref().unwrap();
+		// Create the changefeed entry
+		let change = if stm.is_delete() {
+			TableMutation::Del((*rid).clone())
+		} else if self.is_new() {
+			TableMutation::Set((*rid).clone(), self.current.doc.as_ref().clone())
+		} else {
+			// Store only the changes to existing records
+			let ops = self.initial.doc.diff(&self.current.doc, Idiom::default());
+			TableMutation::Patch((*rid).clone(), ops)
+		};
+		// Write the entry when the transaction commits
+		run.record_change(opt.ns(), opt.db(), &rid.tb, change);
+		// Carry on
+		Ok(())
+	}
+}
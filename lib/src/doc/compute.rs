@@ -18,7 +18,7 @@ impl<'a> Document<'a> {
 		opt: &Options,
 		txn: &Transaction,
 		stm: &Statement<'_>,
-		chn: Sender<Result<Value, Error>>,
+		chn: Sender<(Option<Thing>, Result<Value, Error>)>,
 		thg: Option<Thing>,
 		doc_id: Option<DocId>,
 		val: Operable,
@@ -41,8 +41,10 @@ impl<'a> Document<'a> {
 			Statement::Insert(_) => doc.insert(ctx, opt, txn, stm).await,
 			_ => unreachable!(),
 		};
+		// Drop the document
+		drop(doc);
 		// Send back the result
-		let _ = chn.send(res).await;
+		let _ = chn.send((thg, res)).await;
 		// Everything went ok
 		Ok(())
 	}
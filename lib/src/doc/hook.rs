@@ -0,0 +1,42 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::Statement;
+use crate::doc::Document;
+use crate::err::Error;
+
+impl<'a> Document<'a> {
+	pub async fn hook(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		stm: &Statement<'_>,
+	) -> Result<(), Error> {
+		// Check if a hook is registered
+		let hook = match ctx.hook() {
+			Some(hook) => hook,
+			None => return Ok(()),
+		};
+		// Check if this is a dry run
+		if ctx.is_dry_run() {
+			return Ok(());
+		}
+		// Check if forced
+		if !opt.force && !self.changed() {
+			return Ok(());
+		}
+		// Get the record id
+		let rid = self.id.as_ref().unwrap();
+		// The hook can not be undone if the transaction fails
+		ctx.add_effect();
+		// Run the hook for this type of data change
+		let res = if stm.is_delete() {
+			hook.on_delete(rid, &self.initial.doc)
+		} else if self.is_new() {
+			hook.on_create(rid, &self.current.doc)
+		} else {
+			hook.on_update(rid, &self.initial.doc, &self.current.doc)
+		};
+		// Carry on
+		res.map_err(Error::Hook)
+	}
+}
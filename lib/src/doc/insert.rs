@@ -39,6 +39,10 @@ impl<'a> Document<'a> {
 				self.lives(ctx, opt, txn, stm).await?;
 				// Run event queries
 				self.event(ctx, opt, txn, stm).await?;
+				// Record changefeed entries
+				self.changefeeds(ctx, opt, txn, stm).await?;
+				// Run datastore hooks
+				self.hook(ctx, opt, stm).await?;
 				// Yield document
 				self.pluck(ctx, opt, txn, stm).await
 			}
@@ -66,6 +70,10 @@ impl<'a> Document<'a> {
 				self.lives(ctx, opt, txn, stm).await?;
 				// Run event queries
 				self.event(ctx, opt, txn, stm).await?;
+				// Record changefeed entries
+				self.changefeeds(ctx, opt, txn, stm).await?;
+				// Run datastore hooks
+				self.hook(ctx, opt, stm).await?;
 				// Yield document
 				self.pluck(ctx, opt, txn, stm).await
 			}
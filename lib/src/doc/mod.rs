@@ -20,6 +20,7 @@ mod update; // Processes a UPDATE statement for this document
 
 mod allow; // Checks whether the query can access this document
 mod alter; // Modifies and updates the fields in this document
+mod changefeeds; // Records any changefeed entries for this document
 mod check; // Checks whether the WHERE clauses matches this document
 mod clean; // Ensures records adhere to the table schema
 mod edges; // Attempts to store the edge data for this document
@@ -28,6 +29,7 @@ mod erase; // Removes all content and field data for this document
 mod event; // Processes any table events relevant for this document
 mod exist; // Checks whether the specified document actually exists
 mod field; // Processes any schema-defined fields for this document
+mod hook; // Runs any registered datastore hooks for this document
 mod index; // Attempts to store the index data for this document
 mod lives; // Processes any live queries relevant for this document
 mod merge; // Merges any field changes for an INSERT statement
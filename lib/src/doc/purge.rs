@@ -24,8 +24,10 @@ impl<'a> Document<'a> {
 		if !opt.force && !self.changed() {
 			return Ok(());
 		}
+		// Get the table definition
+		let tb = self.tb(opt, txn).await?;
 		// Check if the table is a view
-		if self.tb(opt, txn).await?.drop {
+		if tb.drop {
 			return Ok(());
 		}
 		// Clone transaction
@@ -37,6 +39,12 @@ impl<'a> Document<'a> {
 			// Purge the record data
 			let key = crate::key::thing::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
 			run.del(key).await?;
+			ctx.add_write(0);
+			// Purge the record expiry
+			if tb.expire.is_some() {
+				let key = crate::key::rx::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
+				run.del(key).await?;
+			}
 			// Purge the record edges
 			match (
 				self.initial.doc.pick(&*EDGE),
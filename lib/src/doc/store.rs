@@ -3,11 +3,12 @@ use crate::dbs::Statement;
 use crate::dbs::{Options, Transaction};
 use crate::doc::Document;
 use crate::err::Error;
+use chrono::Utc;
 
 impl<'a> Document<'a> {
 	pub async fn store(
 		&self,
-		_ctx: &Context<'_>,
+		ctx: &Context<'_>,
 		opt: &Options,
 		txn: &Transaction,
 		_stm: &Statement<'_>,
@@ -16,8 +17,10 @@ impl<'a> Document<'a> {
 		if !opt.force && !self.changed() {
 			return Ok(());
 		}
+		// Get the table definition
+		let tb = self.tb(opt, txn).await?;
 		// Check if the table is a view
-		if self.tb(opt, txn).await?.drop {
+		if tb.drop {
 			return Ok(());
 		}
 		// Claim transaction
@@ -26,7 +29,17 @@ impl<'a> Document<'a> {
 		let rid = self.id.as_ref().unwrap();
 		// Store the record data
 		let key = crate::key::thing::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
-		run.set(key, self).await?;
+		let val: Vec<u8> = self.into();
+		ctx.add_write(val.len());
+		run.set(key, val).await?;
+		// Expire the record once it has not been written for the table expiry
+		if let Some(ex) = &tb.expire {
+			let ts = (Utc::now().timestamp_millis() as u64).saturating_add(ex.as_millis() as u64);
+			let key = crate::key::rx::new(opt.ns(), opt.db(), &rid.tb, &rid.id);
+			run.set(key, crate::key::rx::encode(ts)).await?;
+			let key = crate::key::re::new(ts, opt.ns(), opt.db(), &rid.tb, &rid.id);
+			run.set(key, Vec::<u8>::new()).await?;
+		}
 		// Carry on
 		Ok(())
 	}
@@ -54,6 +54,18 @@ pub enum Error {
 	#[error("The key being inserted already exists")]
 	TxKeyAlreadyExists,
 
+	/// The transaction conflicted with another concurrent transaction
+	#[error("Failed to commit transaction due to a read or write conflict. This transaction can be retried")]
+	TxRetryable,
+
+	/// There are too many write transactions open on this node
+	#[error("The datastore is overloaded with write transactions. Try again later")]
+	TxOverloaded,
+
+	/// The key being accessed is outside of the authenticated namespace or database
+	#[error("Couldn't access a key outside of the authenticated namespace or database")]
+	TxKeyOutOfScope,
+
 	/// The key exceeds a limit set by the KV store
 	#[error("Record id or key is too large")]
 	TxKeyTooLarge,
@@ -66,6 +78,10 @@ pub enum Error {
 	#[error("Transaction is too large")]
 	TxTooLarge,
 
+	/// The query already runs in a single transaction, so it can not begin, commit, or cancel one
+	#[error("Couldn't begin, commit, or cancel a transaction, as the query runs in a single transaction")]
+	TxStatementNotAllowed,
+
 	/// No namespace has been selected
 	#[error("Specify a namespace to use")]
 	NsEmpty,
@@ -82,6 +98,10 @@ pub enum Error {
 	#[error("The SQL query was not parsed fully")]
 	QueryRemaining,
 
+	/// The SQL query contains comments, which would be lost when formatting
+	#[error("The SQL query contains comments, which can not be formatted")]
+	QueryComments,
+
 	/// There was an error with authentication
 	#[error("There was a problem with authentication")]
 	InvalidAuth,
@@ -94,6 +114,10 @@ pub enum Error {
 		sql: String,
 	},
 
+	/// A record write was rejected by a datastore hook
+	#[error("The write was rejected by a datastore hook: {0}")]
+	Hook(String),
+
 	/// There was an error with the provided JSON Patch
 	#[error("The JSON Patch contains invalid operations. {message}")]
 	InvalidPatch {
@@ -104,6 +128,24 @@ pub enum Error {
 	#[error("Remote HTTP request functions are not enabled")]
 	HttpDisabled,
 
+	/// Keys can not be written with a time-to-live
+	#[error("Key expiry is not enabled on the datastore")]
+	KeyExpiryDisabled,
+
+	/// Remote HTTP requests can not be undone, so are not sent in a dry run
+	#[error("Remote HTTP requests are not sent in a dry run")]
+	HttpDryRun,
+
+	/// Remote HTTP requests are not allowed to this host
+	#[error("Remote HTTP requests are not allowed to the host '{host}'")]
+	HttpHostNotAllowed {
+		host: String,
+	},
+
+	/// REMOVE statements are not enabled
+	#[error("REMOVE statements are not enabled")]
+	RemoveDisabled,
+
 	/// it is not possible to set a variable with the specified name
 	#[error("Found '{name}' but it is not possible to set a variable with this name")]
 	InvalidParam {
@@ -140,12 +182,22 @@ pub enum Error {
 		value: String,
 	},
 
-	/// The START clause must evaluate to a positive integer
-	#[error("Found {value} but the START clause must evaluate to a positive integer")]
+	/// The START clause must evaluate to a positive integer or a resume cursor
+	#[error(
+		"Found {value} but the START clause must evaluate to a positive integer, a record id, or an array ending in a record id"
+	)]
 	InvalidStart {
 		value: String,
 	},
 
+	/// The START AFTER cursor does not match the ordering of the results
+	#[error(
+		"The START AFTER cursor {value} must hold the value of each ORDER clause and a record id"
+	)]
+	InvalidCursor {
+		value: String,
+	},
+
 	/// There was an error with the provided JavaScript code
 	#[error("Problem with embedded script function. {message}")]
 	InvalidScript {
@@ -236,6 +288,20 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The backup being restored is not valid
+	#[error("The backup is not a valid SurrealDB backup, or is corrupted")]
+	InvalidBackup,
+
+	/// A backup can only be restored into an empty datastore
+	#[error("The datastore must be empty to restore a backup")]
+	RestoreNotEmpty,
+
+	/// The requested datastore snapshot does not exist
+	#[error("The datastore snapshot '{value}' does not exist")]
+	SnapshotNotFound {
+		value: String,
+	},
+
 	/// The requested function does not exist
 	#[error("The function 'fn::{value}' does not exist")]
 	FcNotFound {
@@ -266,6 +332,12 @@ pub enum Error {
 		value: String,
 	},
 
+	/// The requested sequence does not exist
+	#[error("The sequence '{value}' does not exist")]
+	SqNotFound {
+		value: String,
+	},
+
 	/// The requested param does not exist
 	#[error("The param '${value}' does not exist")]
 	PaNotFound {
@@ -532,6 +604,242 @@ pub enum Error {
 	Unimplemented(String),
 }
 
+impl Error {
+	/// A stable, machine-readable code which identifies the kind of this error
+	///
+	/// Error messages may change between releases, but these codes will not,
+	/// so clients should use these to decide how to handle an error.
+	pub fn kind(&self) -> &'static str {
+		match self {
+			Self::QueryTimedout => "QueryTimeout",
+			Self::QueryCancelled => "QueryCancelled",
+			Self::QueryNotExecuted
+			| Self::QueryNotExecutedDetail {
+				..
+			} => "QueryNotExecuted",
+			Self::QueryEmpty
+			| Self::QueryRemaining
+			| Self::QueryComments
+			| Self::NsEmpty
+			| Self::DbEmpty
+			| Self::ComputationDepthExceeded
+			| Self::TableIsView {
+				..
+			}
+			| Self::NoIndexFoundForMatch {
+				..
+			}
+			| Self::DuplicatedMatchRef {
+				..
+			}
+			| Self::InvalidQuery {
+				..
+			} => "InvalidQuery",
+			Self::InvalidPatch {
+				..
+			}
+			| Self::InvalidParam {
+				..
+			}
+			| Self::InvalidField {
+				..
+			}
+			| Self::InvalidSplit {
+				..
+			}
+			| Self::InvalidOrder {
+				..
+			}
+			| Self::InvalidGroup {
+				..
+			}
+			| Self::InvalidLimit {
+				..
+			}
+			| Self::InvalidStart {
+				..
+			}
+			| Self::InvalidCursor {
+				..
+			}
+			| Self::InvalidArguments {
+				..
+			}
+			| Self::InvalidBackup
+			| Self::RestoreNotEmpty
+			| Self::IdInvalid {
+				..
+			}
+			| Self::LengthInvalid {
+				..
+			}
+			| Self::CoerceTo {
+				..
+			}
+			| Self::ConvertTo {
+				..
+			}
+			| Self::TryAdd(..)
+			| Self::TrySub(..)
+			| Self::TryMul(..)
+			| Self::TryDiv(..)
+			| Self::TryPow(..)
+			| Self::TryNeg(..)
+			| Self::TryFrom(..)
+			| Self::CreateStatement {
+				..
+			}
+			| Self::UpdateStatement {
+				..
+			}
+			| Self::RelateStatement {
+				..
+			}
+			| Self::DeleteStatement {
+				..
+			}
+			| Self::InsertStatement {
+				..
+			}
+			| Self::LiveStatement {
+				..
+			}
+			| Self::KillStatement {
+				..
+			}
+			| Self::TxStatementNotAllowed
+			| Self::AnalyzerError(..)
+			| Self::HighlightError(..) => "InvalidArguments",
+			Self::InvalidScript {
+				..
+			}
+			| Self::InvalidFunction {
+				..
+			} => "InvalidFunction",
+			Self::InvalidAuth
+			| Self::QueryPermissions
+			| Self::NsNotAllowed {
+				..
+			}
+			| Self::DbNotAllowed {
+				..
+			}
+			| Self::TablePermissions {
+				..
+			}
+			| Self::TxKeyOutOfScope => "PermissionDenied",
+			Self::NsNotFound {
+				..
+			}
+			| Self::NtNotFound {
+				..
+			}
+			| Self::NlNotFound {
+				..
+			}
+			| Self::DbNotFound {
+				..
+			}
+			| Self::DtNotFound {
+				..
+			}
+			| Self::DlNotFound {
+				..
+			}
+			| Self::SnapshotNotFound {
+				..
+			}
+			| Self::FcNotFound {
+				..
+			}
+			| Self::ScNotFound {
+				..
+			}
+			| Self::ClNotFound {
+				..
+			}
+			| Self::StNotFound {
+				..
+			}
+			| Self::SqNotFound {
+				..
+			}
+			| Self::PaNotFound {
+				..
+			}
+			| Self::TbNotFound {
+				..
+			}
+			| Self::LvNotFound {
+				..
+			}
+			| Self::LqNotFound {
+				..
+			}
+			| Self::AzNotFound {
+				..
+			}
+			| Self::IxNotFound {
+				..
+			} => "NotFound",
+			Self::ClAlreadyExists {
+				..
+			}
+			| Self::RecordExists {
+				..
+			} => "AlreadyExists",
+			Self::IndexExists {
+				..
+			} => "UniqueConstraint",
+			Self::FieldCheck {
+				..
+			}
+			| Self::FieldValue {
+				..
+			} => "FieldConstraint",
+			Self::TxRetryable => "TxConflict",
+			Self::TxOverloaded => "Overloaded",
+			Self::TxFailure
+			| Self::TxFinished
+			| Self::TxReadonly
+			| Self::TxConditionNotMet
+			| Self::TxKeyAlreadyExists
+			| Self::TxKeyTooLarge
+			| Self::TxValueTooLarge
+			| Self::TxTooLarge
+			| Self::Tx(..)
+			| Self::Ds(..) => "TxFailure",
+			Self::HttpDisabled
+			| Self::KeyExpiryDisabled
+			| Self::HttpDryRun
+			| Self::HttpHostNotAllowed {
+				..
+			}
+			| Self::RemoveDisabled
+			| Self::RealtimeDisabled
+			| Self::FeatureNotYetImplemented {
+				..
+			}
+			| Self::Unimplemented(..) => "Disabled",
+			Self::Http(..) => "Http",
+			Self::Hook(..) => "Hook",
+			Self::Ignore
+			| Self::Unreachable
+			| Self::BypassQueryPlanner
+			| Self::CorruptedIndex
+			| Self::Channel(..)
+			| Self::Serde(..)
+			| Self::Encode(..)
+			| Self::Decode(..)
+			| Self::Bincode(..)
+			| Self::FstError(..)
+			| Self::Utf8Error(..)
+			| Self::TimestampOverflow(..)
+			| Self::Internal(..) => "Internal",
+		}
+	}
+}
+
 impl From<Error> for String {
 	fn from(e: Error) -> String {
 		e.to_string()
@@ -555,6 +863,8 @@ impl From<echodb::err::Error> for Error {
 	fn from(e: echodb::err::Error) -> Error {
 		match e {
 			echodb::err::Error::KeyAlreadyExists => Error::TxKeyAlreadyExists,
+			// Write transactions are serialised by echodb, so
+			// there is no conflict error to map to TxRetryable
 			_ => Error::Tx(e.to_string()),
 		}
 	}
@@ -579,6 +889,10 @@ impl From<tikv::Error> for Error {
 				abort,
 				..
 			}) if abort.contains("KeyTooLarge") => Error::TxKeyTooLarge,
+			tikv::Error::KeyError(tikv_client_proto::kvrpcpb::KeyError {
+				conflict: Some(_),
+				..
+			}) => Error::TxRetryable,
 			tikv::Error::RegionError(tikv_client_proto::errorpb::Error {
 				raft_entry_too_large,
 				..
@@ -591,14 +905,20 @@ impl From<tikv::Error> for Error {
 #[cfg(feature = "kv-speedb")]
 impl From<speedb::Error> for Error {
 	fn from(e: speedb::Error) -> Error {
-		Error::Tx(e.to_string())
+		match e.kind() {
+			speedb::ErrorKind::Busy | speedb::ErrorKind::TryAgain => Error::TxRetryable,
+			_ => Error::Tx(e.to_string()),
+		}
 	}
 }
 
 #[cfg(feature = "kv-rocksdb")]
 impl From<rocksdb::Error> for Error {
 	fn from(e: rocksdb::Error) -> Error {
-		Error::Tx(e.to_string())
+		match e.kind() {
+			rocksdb::ErrorKind::Busy | rocksdb::ErrorKind::TryAgain => Error::TxRetryable,
+			_ => Error::Tx(e.to_string()),
+		}
 	}
 }
 
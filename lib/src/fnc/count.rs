@@ -1,4 +1,8 @@
+use crate::dbs::Level;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
 use crate::err::Error;
+use crate::idx::hll::HyperLogLog;
 use crate::sql::value::Value;
 
 pub fn count((arg,): (Option<Value>,)) -> Result<Value, Error> {
@@ -9,3 +13,40 @@ pub fn count((arg,): (Option<Value>,)) -> Result<Value, Error> {
 		})
 		.unwrap_or_else(|| 1.into()))
 }
+
+/// Estimates the number of records in a table, without scanning it
+///
+/// The estimate is read from the sketch of the records in the table which
+/// was built by the last ANALYZE TABLE statement, so it does not include
+/// any writes since then. NONE is returned if the table was never analyzed.
+pub async fn estimate(
+	(opt, txn): (Option<&Options>, Option<&Transaction>),
+	(table,): (Value,),
+) -> Result<Value, Error> {
+	if let (Some(opt), Some(txn)) = (opt, txn) {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Get the table name
+		let tb = match table {
+			Value::Table(v) => v.0,
+			Value::Strand(v) => v.0,
+			_ => {
+				return Err(Error::InvalidArguments {
+					name: String::from("count::estimate"),
+					message: String::from("The argument must be a table."),
+				})
+			}
+		};
+		// Claim transaction
+		let mut run = txn.lock().await;
+		// Load the sketch of the records in the table
+		let key = crate::key::hc::new(opt.ns(), opt.db(), &tb);
+		return Ok(match HyperLogLog::load(&mut run, key.into()).await? {
+			Some(hll) => hll.estimate().into(),
+			None => Value::None,
+		});
+	}
+	Ok(Value::None)
+}
@@ -88,4 +88,13 @@ pub mod hash {
 			_ => Ok(Value::None),
 		}
 	}
+
+	pub fn neighbours((arg,): (Value,)) -> Result<Value, Error> {
+		match arg {
+			Value::Strand(v) => {
+				Ok(geo::neighbours(v).into_iter().map(Value::from).collect::<Vec<_>>().into())
+			}
+			_ => Ok(Value::None),
+		}
+	}
 }
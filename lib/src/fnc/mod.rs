@@ -1,5 +1,6 @@
 //! Executes functions from SQL. If there is an SQL function it will be defined in this module.
 use crate::ctx::Context;
+use crate::dbs::Options;
 use crate::dbs::Transaction;
 use crate::doc::CursorDoc;
 use crate::err::Error;
@@ -20,9 +21,11 @@ pub mod meta;
 pub mod not;
 pub mod operate;
 pub mod parse;
+pub mod patch;
 pub mod rand;
 pub mod script;
 pub mod search;
+pub mod sequence;
 pub mod session;
 pub mod sleep;
 pub mod string;
@@ -34,20 +37,23 @@ pub mod vector;
 /// Attempts to run any function
 pub async fn run(
 	ctx: &Context<'_>,
+	opt: &Options,
 	txn: &Transaction,
 	doc: Option<&CursorDoc<'_>>,
 	name: &str,
 	args: Vec<Value>,
 ) -> Result<Value, Error> {
 	if name.eq("sleep")
+		|| name.starts_with("count::")
 		|| name.starts_with("search")
+		|| name.starts_with("sequence")
 		|| name.starts_with("http")
 		|| name.starts_with("crypto::argon2")
 		|| name.starts_with("crypto::bcrypt")
 		|| name.starts_with("crypto::pbkdf2")
 		|| name.starts_with("crypto::scrypt")
 	{
-		asynchronous(ctx, Some(txn), doc, name, args).await
+		asynchronous(ctx, Some(opt), Some(txn), doc, name, args).await
 	} else {
 		synchronous(ctx, name, args)
 	}
@@ -154,6 +160,7 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 		"geo::distance" => geo::distance,
 		"geo::hash::decode" => geo::hash::decode,
 		"geo::hash::encode" => geo::hash::encode,
+		"geo::hash::neighbours" => geo::hash::neighbours,
 		//
 		"is::alphanum" => is::alphanum,
 		"is::alpha" => is::alpha,
@@ -210,6 +217,9 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 		"parse::url::query" => parse::url::query,
 		"parse::url::scheme" => parse::url::scheme,
 		//
+		"patch" => patch::patch,
+		"patch::merge" => patch::merge,
+		//
 		"rand" => rand::rand,
 		"rand::bool" => rand::bool,
 		"rand::enum" => rand::r#enum,
@@ -307,6 +317,7 @@ pub fn synchronous(ctx: &Context<'_>, name: &str, args: Vec<Value>) -> Result<Va
 /// Attempts to run any asynchronous function.
 pub async fn asynchronous(
 	ctx: &Context<'_>,
+	opt: Option<&Options>,
 	txn: Option<&Transaction>,
 	doc: Option<&CursorDoc<'_>>,
 	name: &str,
@@ -340,6 +351,8 @@ pub async fn asynchronous(
 		"crypto::scrypt::compare" => (cpu_intensive) crypto::scrypt::cmp.await,
 		"crypto::scrypt::generate" => (cpu_intensive) crypto::scrypt::gen.await,
 		//
+		"count::estimate" => count::estimate((opt, txn)).await,
+		//
 		"http::head" => http::head(ctx).await,
 		"http::get" => http::get(ctx).await,
 		"http::put" => http::put(ctx).await,
@@ -351,6 +364,8 @@ pub async fn asynchronous(
 		"search::highlight" => search::highlight((ctx,txn, doc)).await,
 		"search::offsets" => search::offsets((ctx, txn, doc)).await,
 		//
+		"sequence::next" => sequence::next((ctx, opt, txn)).await,
+		//
 		"sleep" => sleep::sleep(ctx).await,
 	)
 }
@@ -0,0 +1,14 @@
+use crate::err::Error;
+use crate::sql::value::Value;
+
+/// Applies a set of JSON Patch (RFC 6902) operations to a value.
+pub fn patch((mut val, ops): (Value, Value)) -> Result<Value, Error> {
+	val.patch(ops)?;
+	Ok(val)
+}
+
+/// Applies a JSON Merge Patch (RFC 7386) document to a value.
+pub fn merge((mut val, patch): (Value, Value)) -> Result<Value, Error> {
+	val.merge_patch(patch);
+	Ok(val)
+}
@@ -1,5 +1,6 @@
 use crate::cnf::ID_CHARS;
 use crate::err::Error;
+use crate::fnc::util::ulid;
 use crate::sql::uuid::Uuid;
 use crate::sql::value::Value;
 use chrono::{TimeZone, Utc};
@@ -7,7 +8,6 @@ use nanoid::nanoid;
 use rand::distributions::{Alphanumeric, DistString};
 use rand::prelude::IteratorRandom;
 use rand::Rng;
-use ulid::Ulid;
 
 pub fn rand(_: ()) -> Result<Value, Error> {
 	Ok(rand::random::<f64>().into())
@@ -151,7 +151,7 @@ pub fn time((range,): (Option<(i64, i64)>,)) -> Result<Value, Error> {
 }
 
 pub fn ulid(_: ()) -> Result<Value, Error> {
-	Ok(Ulid::new().to_string().into())
+	Ok(ulid::monotonic().to_string().into())
 }
 
 pub fn uuid(_: ()) -> Result<Value, Error> {
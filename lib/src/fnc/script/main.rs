@@ -39,6 +39,8 @@ pub async fn run(
 	let cancellation = ctx.cancellation();
 	let handler = Box::new(move || cancellation.is_done());
 	run.set_interrupt_handler(Some(handler)).await;
+	// Check whether the fetch function can be used
+	let fetch = ctx.capabilities().allows_any_host();
 	// Create an execution context
 	let ctx = js::AsyncContext::full(&run).await.unwrap();
 	// Set the module resolver and loader
@@ -60,7 +62,10 @@ pub async fn run(
 				Module::evaluate_def::<modules::surrealdb::Package, _>(ctx, "surrealdb")?
 					.get::<_, js::Value>("default")?,
 			)?;
-			fetch::register(ctx)?;
+			// Register the fetch function to the globals
+			if fetch {
+				fetch::register(ctx)?;
+			}
 			// Register the console function to the globals
 			global.init_def::<globals::console::Console>()?;
 			// Register the special SurrealDB types as classes
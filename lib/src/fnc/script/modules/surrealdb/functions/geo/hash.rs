@@ -7,5 +7,6 @@ impl_module_def!(
 	Package,
 	"geo::hash",
 	"encode" => run,
-	"decode" => run
+	"decode" => run,
+	"neighbours" => run
 );
@@ -7,6 +7,7 @@ use js::Result;
 
 mod array;
 mod bytes;
+mod count;
 mod crypto;
 mod duration;
 mod encoding;
@@ -16,8 +17,10 @@ mod is;
 mod math;
 mod meta;
 mod parse;
+mod patch;
 mod rand;
 mod search;
+mod sequence;
 mod session;
 mod string;
 mod time;
@@ -31,7 +34,7 @@ impl_module_def!(
 	"", // root path
 	"array" => (array::Package),
 	"bytes" => (bytes::Package),
-	"count" => run,
+	"count" => (count::Package),
 	"crypto" => (crypto::Package),
 	"duration" => (duration::Package),
 	"encoding" => (encoding::Package),
@@ -42,9 +45,11 @@ impl_module_def!(
 	"meta" => (meta::Package),
 	"not" => run,
 	"parse" => (parse::Package),
+	"patch" => (patch::Package),
 	"rand" => (rand::Package),
 	"array" => (array::Package),
 	"search" => (search::Package),
+	"sequence" => (sequence::Package),
 	"session" => (session::Package),
 	"sleep" => fut Async,
 	"string" => (string::Package),
@@ -70,7 +75,7 @@ async fn fut(js_ctx: js::Ctx<'_>, name: &str, args: Vec<Value>) -> Result<Value>
 	// Create a default context
 	let ctx = Context::background();
 	// Process the called function
-	let res = fnc::asynchronous(&ctx, None, None, name, args).await;
+	let res = fnc::asynchronous(&ctx, None, None, None, name, args).await;
 	// Convert any response error
 	res.map_err(|err| {
 		js::Exception::from_message(js_ctx, &err.to_string())
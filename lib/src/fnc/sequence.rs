@@ -0,0 +1,23 @@
+use crate::ctx::Context;
+use crate::dbs::Level;
+use crate::dbs::Options;
+use crate::dbs::Transaction;
+use crate::err::Error;
+use crate::sql::Value;
+
+pub async fn next(
+	(ctx, opt, txn): (&Context<'_>, Option<&Options>, Option<&Transaction>),
+	(name,): (String,),
+) -> Result<Value, Error> {
+	if let (Some(opt), Some(txn)) = (opt, txn) {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Advance the sequence
+		let val = match ctx.datastore() {
+			Some(ds) => ds.next_sn(ctx, txn, opt.ns(), opt.db(), &name).await?,
+			None => txn.lock().await.next_sn(opt.ns(), opt.db(), &name).await?,
+		};
+		return Ok(val.into());
+	}
+	Ok(Value::None)
+}
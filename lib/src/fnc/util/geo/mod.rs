@@ -99,3 +99,57 @@ pub fn decode(v: Strand) -> Geometry {
 
 	(x, y).into()
 }
+
+/// Returns the geohash cells surrounding the given cell, ordered clockwise
+/// from the north, and wrapping around the antimeridian. Cells which would
+/// fall beyond either of the poles are omitted.
+pub fn neighbours(v: Strand) -> Vec<Strand> {
+	let len = v.as_str().len();
+	// Calculate the size of a cell at this precision
+	let lon_bits = (5 * len + 1) / 2;
+	let lat_bits = 5 * len / 2;
+	let w = 360f64 / 2f64.powi(lon_bits as i32);
+	let h = 180f64 / 2f64.powi(lat_bits as i32);
+	// Find the centre of the cell
+	let (x, y) = match decode(v) {
+		Geometry::Point(v) => (v.x(), v.y()),
+		_ => return Vec::new(),
+	};
+	// Encode the centre of each adjacent cell
+	[(0, 1), (1, 1), (1, 0), (1, -1), (0, -1), (-1, -1), (-1, 0), (-1, 1)]
+		.iter()
+		.filter_map(|(dx, dy)| {
+			let lat = y + *dy as f64 * h;
+			if !(-90f64..=90f64).contains(&lat) {
+				return None;
+			}
+			let mut lon = x + *dx as f64 * w;
+			if lon > 180f64 {
+				lon -= 360f64;
+			} else if lon < -180f64 {
+				lon += 360f64;
+			}
+			Some(encode(Point::new(lon, lat), len))
+		})
+		.collect()
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn neighbours_inner() {
+		let res = neighbours(Strand::from("gbsuv"));
+		let exp = ["gbsvj", "gbsvn", "gbsuy", "gbsuw", "gbsut", "gbsus", "gbsuu", "gbsvh"];
+		assert_eq!(res, exp.into_iter().map(Strand::from).collect::<Vec<_>>());
+	}
+
+	#[test]
+	fn neighbours_edges() {
+		let res = neighbours(Strand::from("b"));
+		let exp = ["c", "9", "8", "x", "z"];
+		assert_eq!(res, exp.into_iter().map(Strand::from).collect::<Vec<_>>());
+	}
+}
@@ -11,6 +11,49 @@ pub(crate) fn uri_is_valid(uri: &str) -> bool {
 	reqwest::Url::parse(uri).is_ok()
 }
 
+fn client(ctx: &Context<'_>, uri: &str) -> Result<Client, Error> {
+	let caps = ctx.capabilities();
+	// Check that outbound requests are allowed
+	if !caps.allows_http() {
+		return Err(Error::HttpDisabled);
+	}
+	// Check that this is not a dry run
+	if ctx.is_dry_run() {
+		return Err(Error::HttpDryRun);
+	}
+	// Check that the requested host is allowed
+	if !caps.allows_any_host() {
+		let host = reqwest::Url::parse(uri)
+			.ok()
+			.and_then(|v| v.host_str().map(str::to_owned))
+			.unwrap_or_default();
+		if !caps.allows_host(&host) {
+			return Err(Error::HttpHostNotAllowed {
+				host,
+			});
+		}
+		// Redirects could lead to a host which is not allowed
+		#[cfg(not(target_arch = "wasm32"))]
+		return Ok(Client::builder().redirect(reqwest::redirect::Policy::none()).build()?);
+	}
+	Ok(Client::builder().build()?)
+}
+
+// When running in WebAssembly, redirects are followed by the browser and
+// can not be switched off, so check the host which the response came from
+fn check_host(ctx: &Context<'_>, res: &Response) -> Result<(), Error> {
+	let caps = ctx.capabilities();
+	if !caps.allows_any_host() {
+		let host = res.url().host_str().unwrap_or_default();
+		if !caps.allows_host(host) {
+			return Err(Error::HttpHostNotAllowed {
+				host: host.to_owned(),
+			});
+		}
+	}
+	Ok(())
+}
+
 fn encode_body(req: RequestBuilder, body: Value) -> RequestBuilder {
 	match body {
 		Value::Bytes(bytes) => req.header(CONTENT_TYPE, "application/octet-stream").body(bytes.0),
@@ -47,7 +90,7 @@ async fn decode_response(res: Response) -> Result<Value, Error> {
 
 pub async fn head(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Result<Value, Error> {
 	// Set a default client with no timeout
-	let cli = Client::builder().build()?;
+	let cli = client(ctx, uri.as_str())?;
 	// Start a new HEAD request
 	let mut req = cli.head(uri.as_str());
 	// Add the User-Agent header
@@ -58,12 +101,16 @@ pub async fn head(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Re
 	for (k, v) in opts.into().iter() {
 		req = req.header(k.as_str(), v.to_raw_string());
 	}
+	// The request can not be undone if the transaction fails
+	ctx.add_effect();
 	// Send the request and wait
 	let res = match ctx.timeout() {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
 	};
+	// Check the host which responded
+	check_host(ctx, &res)?;
 	// Check the response status
 	match res.status() {
 		s if s.is_success() => Ok(Value::None),
@@ -73,7 +120,7 @@ pub async fn head(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Re
 
 pub async fn get(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Result<Value, Error> {
 	// Set a default client with no timeout
-	let cli = Client::builder().build()?;
+	let cli = client(ctx, uri.as_str())?;
 	// Start a new GET request
 	let mut req = cli.get(uri.as_str());
 	// Add the User-Agent header
@@ -84,12 +131,16 @@ pub async fn get(ctx: &Context<'_>, uri: Strand, opts: impl Into<Object>) -> Res
 	for (k, v) in opts.into().iter() {
 		req = req.header(k.as_str(), v.to_raw_string());
 	}
+	// The request can not be undone if the transaction fails
+	ctx.add_effect();
 	// Send the request and wait
 	let res = match ctx.timeout() {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
 	};
+	// Check the host which responded
+	check_host(ctx, &res)?;
 	// Receive the response as a value
 	decode_response(res).await
 }
@@ -101,7 +152,7 @@ pub async fn put(
 	opts: impl Into<Object>,
 ) -> Result<Value, Error> {
 	// Set a default client with no timeout
-	let cli = Client::builder().build()?;
+	let cli = client(ctx, uri.as_str())?;
 	// Start a new GET request
 	let mut req = cli.put(uri.as_str());
 	// Add the User-Agent header
@@ -114,12 +165,16 @@ pub async fn put(
 	}
 	// Submit the request body
 	req = encode_body(req, body);
+	// The request can not be undone if the transaction fails
+	ctx.add_effect();
 	// Send the request and wait
 	let res = match ctx.timeout() {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
 	};
+	// Check the host which responded
+	check_host(ctx, &res)?;
 	// Receive the response as a value
 	decode_response(res).await
 }
@@ -131,7 +186,7 @@ pub async fn post(
 	opts: impl Into<Object>,
 ) -> Result<Value, Error> {
 	// Set a default client with no timeout
-	let cli = Client::builder().build()?;
+	let cli = client(ctx, uri.as_str())?;
 	// Start a new GET request
 	let mut req = cli.post(uri.as_str());
 	// Add the User-Agent header
@@ -144,12 +199,16 @@ pub async fn post(
 	}
 	// Submit the request body
 	req = encode_body(req, body);
+	// The request can not be undone if the transaction fails
+	ctx.add_effect();
 	// Send the request and wait
 	let res = match ctx.timeout() {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
 	};
+	// Check the host which responded
+	check_host(ctx, &res)?;
 	// Receive the response as a value
 	decode_response(res).await
 }
@@ -161,7 +220,7 @@ pub async fn patch(
 	opts: impl Into<Object>,
 ) -> Result<Value, Error> {
 	// Set a default client with no timeout
-	let cli = Client::builder().build()?;
+	let cli = client(ctx, uri.as_str())?;
 	// Start a new GET request
 	let mut req = cli.patch(uri.as_str());
 	// Add the User-Agent header
@@ -174,12 +233,16 @@ pub async fn patch(
 	}
 	// Submit the request body
 	req = encode_body(req, body);
+	// The request can not be undone if the transaction fails
+	ctx.add_effect();
 	// Send the request and wait
 	let res = match ctx.timeout() {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
 	};
+	// Check the host which responded
+	check_host(ctx, &res)?;
 	// Receive the response as a value
 	decode_response(res).await
 }
@@ -190,7 +253,7 @@ pub async fn delete(
 	opts: impl Into<Object>,
 ) -> Result<Value, Error> {
 	// Set a default client with no timeout
-	let cli = Client::builder().build()?;
+	let cli = client(ctx, uri.as_str())?;
 	// Start a new GET request
 	let mut req = cli.delete(uri.as_str());
 	// Add the User-Agent header
@@ -201,12 +264,16 @@ pub async fn delete(
 	for (k, v) in opts.into().iter() {
 		req = req.header(k.as_str(), v.to_raw_string());
 	}
+	// The request can not be undone if the transaction fails
+	ctx.add_effect();
 	// Send the request and wait
 	let res = match ctx.timeout() {
 		#[cfg(not(target_arch = "wasm32"))]
 		Some(d) => req.timeout(d).send().await?,
 		_ => req.send().await?,
 	};
+	// Check the host which responded
+	check_host(ctx, &res)?;
 	// Receive the response as a value
 	decode_response(res).await
 }
@@ -1,6 +1,7 @@
 pub mod geo;
 pub mod math;
 pub mod string;
+pub mod ulid;
 
 #[cfg(feature = "http")]
 pub mod http;
@@ -0,0 +1,37 @@
+use once_cell::sync::Lazy;
+use std::sync::Mutex;
+use ulid::{Generator, Ulid};
+
+/// A shared generator, so that ULIDs created within the same millisecond,
+/// or after the system clock moves backwards, are still strictly increasing
+static GENERATOR: Lazy<Mutex<Generator>> = Lazy::new(|| Mutex::new(Generator::new()));
+
+/// Generates a new ULID which sorts after every ULID previously generated by this process
+pub fn monotonic() -> Ulid {
+	// A poisoned lock still holds the last generated ULID
+	let mut gen = GENERATOR.lock().unwrap_or_else(|e| e.into_inner());
+	loop {
+		match gen.generate() {
+			Ok(v) => return v,
+			// Every ULID in this millisecond has been used, so wait for the next one
+			Err(_) => std::hint::spin_loop(),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn monotonic_ordering() {
+		let mut last = monotonic();
+		for _ in 0..10_000 {
+			let next = monotonic();
+			assert!(next > last);
+			assert!(next.to_string() > last.to_string());
+			last = next;
+		}
+	}
+}
@@ -51,6 +51,13 @@ pub struct Claims {
 	#[serde(alias = "https://surrealdb.com/record")]
 	#[serde(skip_serializing_if = "Option::is_none")]
 	pub id: Option<String>,
+	#[serde(alias = "pr")]
+	#[serde(alias = "PR")]
+	#[serde(rename = "PR")]
+	#[serde(alias = "https://surrealdb.com/pr")]
+	#[serde(alias = "https://surrealdb.com/priority")]
+	#[serde(skip_serializing_if = "Option::is_none")]
+	pub pr: Option<String>,
 }
 
 impl From<Claims> for Value {
@@ -93,6 +100,10 @@ impl From<Claims> for Value {
 		if let Some(id) = v.id {
 			out.insert("ID".to_string(), id.into());
 		}
+		// Add PR field if set
+		if let Some(pr) = v.pr {
+			out.insert("PR".to_string(), pr.into());
+		}
 		// Return value
 		out.into()
 	}
@@ -4,6 +4,7 @@ use crate::err::Error;
 use crate::iam::token::Claims;
 use crate::iam::TOKEN;
 use crate::kvs::Datastore;
+use crate::kvs::Priority;
 use crate::sql::Algorithm;
 use crate::sql::Value;
 use chrono::Utc;
@@ -11,6 +12,25 @@ use jsonwebtoken::{decode, DecodingKey, Validation};
 use once_cell::sync::Lazy;
 use std::sync::Arc;
 
+fn priority(pr: &str) -> Priority {
+	match pr.to_ascii_lowercase().as_str() {
+		"batch" | "low" => Priority::Low,
+		"interactive" | "normal" => Priority::Normal,
+		// The highest priority is kept for the upkeep of the datastore
+		"system" | "high" => {
+			warn!(
+				"The 'PR' field in the authentication token can not be '{pr}', so 'normal' is used"
+			);
+			Priority::Normal
+		}
+		// An unknown priority does not invalidate the token
+		_ => {
+			warn!("The 'PR' field in the authentication token was unknown, so 'normal' is used");
+			Priority::Normal
+		}
+	}
+}
+
 fn config(algo: Algorithm, code: String) -> Result<(DecodingKey, Validation), Error> {
 	match algo {
 		Algorithm::Hs256 => Ok((
@@ -101,6 +121,8 @@ pub async fn token(kvs: &Datastore, session: &mut Session, auth: String) -> Resu
 			return Err(Error::InvalidAuth);
 		}
 	}
+	// Check the priority of the requests made with the token
+	let pr = token.claims.pr.as_deref().map(priority);
 	// Check the token authentication claims
 	match token.claims {
 		// Check if this is scope token authentication
@@ -265,5 +287,10 @@ pub async fn token(kvs: &Datastore, session: &mut Session, auth: String) -> Resu
 		}
 		// There was an auth error
 		_ => Err(Error::InvalidAuth),
+	}?;
+	// Set the priority of the session
+	if let Some(pr) = pr {
+		session.pr = pr;
 	}
+	Ok(())
 }
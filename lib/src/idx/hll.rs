@@ -0,0 +1,105 @@
+use crate::err::Error;
+use crate::kvs::{Key, Transaction};
+use sha2::{Digest, Sha256};
+
+/// The number of bits of each hash which select a register
+const PRECISION: u32 = 10;
+
+/// The number of registers, giving a standard error of about 3%
+const REGISTERS: usize = 1 << PRECISION;
+
+/// A HyperLogLog sketch, which estimates the number of distinct
+/// values which have been added to it, using a fixed amount of space
+///
+/// Sketches are built on demand by scanning the keys, rather than on
+/// every write, so that concurrent writers do not conflict on them.
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub(crate) struct HyperLogLog {
+	registers: Vec<u8>,
+}
+
+impl Default for HyperLogLog {
+	fn default() -> Self {
+		Self {
+			registers: vec![0; REGISTERS],
+		}
+	}
+}
+
+impl HyperLogLog {
+	/// Load a sketch from the datastore, if one has been stored
+	pub(crate) async fn load(tx: &mut Transaction, key: Key) -> Result<Option<Self>, Error> {
+		Ok(match tx.get(key).await? {
+			Some(v) if v.len() == REGISTERS => Some(Self {
+				registers: v,
+			}),
+			_ => None,
+		})
+	}
+
+	/// Store the sketch in the datastore
+	pub(crate) async fn save(&self, tx: &mut Transaction, key: Key) -> Result<(), Error> {
+		tx.set(key, self.registers.clone()).await
+	}
+
+	/// Add a value to the sketch
+	pub(crate) fn add(&mut self, val: &[u8]) {
+		let hash = Sha256::digest(val);
+		let hash = u64::from_be_bytes(hash[..8].try_into().unwrap());
+		// The first bits of the hash select the register
+		let idx = (hash >> (64 - PRECISION)) as usize;
+		// The register holds the longest run of leading zeros seen
+		let rho = ((hash << PRECISION) | (1 << (PRECISION - 1))).leading_zeros() as u8 + 1;
+		if rho > self.registers[idx] {
+			self.registers[idx] = rho;
+		}
+	}
+
+	/// Estimate the number of distinct values which have been added
+	pub(crate) fn estimate(&self) -> u64 {
+		let m = REGISTERS as f64;
+		let alpha = 0.7213 / (1.0 + 1.079 / m);
+		let sum: f64 = self.registers.iter().map(|&r| 2f64.powi(-(r as i32))).sum();
+		let est = alpha * m * m / sum;
+		// Use linear counting for small cardinalities
+		let zeros = self.registers.iter().filter(|&&r| r == 0).count();
+		let est = match est <= 2.5 * m && zeros > 0 {
+			true => m * (m / zeros as f64).ln(),
+			false => est,
+		};
+		est.round() as u64
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn estimate_empty() {
+		let hll = HyperLogLog::default();
+		assert_eq!(hll.estimate(), 0);
+	}
+
+	#[test]
+	fn estimate_duplicates() {
+		let mut hll = HyperLogLog::default();
+		for _ in 0..100 {
+			hll.add(b"test");
+		}
+		assert_eq!(hll.estimate(), 1);
+	}
+
+	#[test]
+	fn estimate_distinct() {
+		for count in [100, 10_000, 100_000] {
+			let mut hll = HyperLogLog::default();
+			for i in 0..count {
+				hll.add(format!("person:{i}").as_bytes());
+			}
+			let est = hll.estimate() as f64;
+			let err = (est - count as f64).abs() / count as f64;
+			assert!(err < 0.1, "estimated {est} for {count} values");
+		}
+	}
+}
@@ -1,6 +1,7 @@
 pub mod bkeys;
 pub mod btree;
 pub(crate) mod ft;
+pub(crate) mod hll;
 pub(crate) mod planner;
 
 use crate::dbs::Options;
@@ -0,0 +1,70 @@
+use crate::sql::Idiom;
+use std::collections::BTreeMap;
+use std::sync::Mutex;
+
+/// A field which was compared with a value in a condition which
+/// caused a full table scan, and which could have used an index
+#[derive(Clone, Debug, Eq, PartialEq)]
+pub(crate) struct Suggestion {
+	/// The table which was scanned
+	pub tb: String,
+	/// The field which could be indexed
+	pub field: Idiom,
+	/// The number of full table scans which an index would have avoided
+	pub scans: u64,
+}
+
+/// Records the full table scans which could have used an index
+#[derive(Debug, Default)]
+pub(crate) struct Advisor(Mutex<BTreeMap<(String, String, String, String), Suggestion>>);
+
+impl Advisor {
+	/// Record a full table scan which an index on the field would have avoided
+	pub fn add(&self, ns: &str, db: &str, tb: &str, field: &Idiom) {
+		let mut suggestions = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		let key = (ns.to_owned(), db.to_owned(), tb.to_owned(), field.to_string());
+		suggestions
+			.entry(key)
+			.or_insert_with(|| Suggestion {
+				tb: tb.to_owned(),
+				field: field.clone(),
+				scans: 0,
+			})
+			.scans += 1;
+	}
+	/// Get the suggested indexes for a database, with the most scans first
+	pub fn get(&self, ns: &str, db: &str) -> Vec<Suggestion> {
+		let suggestions = self.0.lock().unwrap_or_else(|e| e.into_inner());
+		let mut res: Vec<Suggestion> = suggestions
+			.iter()
+			.filter(|((n, d, _, _), _)| n == ns && d == db)
+			.map(|(_, v)| v.clone())
+			.collect();
+		res.sort_by(|a, b| b.scans.cmp(&a.scans));
+		res
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn advisor() {
+		let adv = Advisor::default();
+		let email = Idiom::from("email".to_owned());
+		let name = Idiom::from("name".to_owned());
+		adv.add("test", "test", "person", &name);
+		adv.add("test", "test", "person", &email);
+		adv.add("test", "test", "person", &email);
+		adv.add("test", "other", "person", &email);
+		let res = adv.get("test", "test");
+		assert_eq!(res.len(), 2);
+		assert_eq!(res[0].field, email);
+		assert_eq!(res[0].scans, 2);
+		assert_eq!(res[1].field, name);
+		assert_eq!(res[1].scans, 1);
+		assert_eq!(adv.get("test", "other").len(), 1);
+		assert!(adv.get("test", "none").is_empty());
+	}
+}
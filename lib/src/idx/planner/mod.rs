@@ -1,3 +1,4 @@
+pub(crate) mod advisor;
 pub(crate) mod executor;
 pub(crate) mod plan;
 mod tree;
@@ -8,7 +9,7 @@ use crate::err::Error;
 use crate::idx::planner::executor::QueryExecutor;
 use crate::idx::planner::plan::{Plan, PlanBuilder};
 use crate::idx::planner::tree::{Node, Tree};
-use crate::sql::{Cond, Operator, Table};
+use crate::sql::{Cond, Idiom, Operator, Table};
 use std::collections::HashMap;
 
 pub(crate) struct QueryPlanner<'a> {
@@ -36,13 +37,26 @@ impl<'a> QueryPlanner<'a> {
 		let res = Tree::build(ctx, self.opt, txn, &t, self.cond).await?;
 		if let Some((node, im)) = res {
 			if let Some(plan) = AllAndStrategy::build(&node)? {
+				ctx.add_index(&plan.i.ix().name.0);
 				let e = QueryExecutor::new(self.opt, txn, &t, im, Some(plan.e.clone())).await?;
 				self.executors.insert(t.0.clone(), e);
 				return Ok(Iterable::Index(t, plan));
 			}
+			// Record a field which could be indexed to avoid this scan
+			if let (Some(field), Some(ds), false) =
+				(SuggestStrategy::build(&node), ctx.datastore(), ctx.is_dry_run())
+			{
+				ds.add_suggestion(self.opt.ns(), self.opt.db(), &t.0, field);
+			}
 			let e = QueryExecutor::new(self.opt, txn, &t, im, None).await?;
 			self.executors.insert(t.0.clone(), e);
 		}
+		// Let the user know that the condition could not use an index
+		if self.cond.is_some() {
+			ctx.add_warning(format!(
+				"The query on table '{t}' does not use an index, so the whole table is scanned"
+			));
+		}
 		Ok(Iterable::Table(t))
 	}
 
@@ -104,3 +118,47 @@ impl AllAndStrategy {
 		Ok(())
 	}
 }
+
+struct SuggestStrategy<'a> {
+	field: Option<&'a Idiom>,
+}
+
+/// Finds a field which is compared with a value, and which would let
+/// the AllAndStrategy use an index if it had a non-unique index
+impl<'a> SuggestStrategy<'a> {
+	fn build(node: &'a Node) -> Option<&'a Idiom> {
+		let mut s = SuggestStrategy {
+			field: None,
+		};
+		match s.eval_node(node) {
+			true => s.field,
+			false => None,
+		}
+	}
+
+	fn eval_node(&mut self, node: &'a Node) -> bool {
+		match node {
+			Node::Expression {
+				left,
+				right,
+				exp: expression,
+				..
+			} => {
+				let op = expression.operator();
+				if op.eq(&Operator::Or) {
+					return false;
+				}
+				if op.eq(&Operator::Equal) && self.field.is_none() {
+					match (left.as_ref(), right.as_ref()) {
+						(Node::NonIndexedField(i), Node::Scalar(_)) => self.field = Some(i),
+						(Node::Scalar(_), Node::NonIndexedField(i)) => self.field = Some(i),
+						_ => (),
+					}
+				}
+				self.eval_node(left) && self.eval_node(right)
+			}
+			Node::Unsupported => false,
+			_ => true,
+		}
+	}
+}
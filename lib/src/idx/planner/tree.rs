@@ -91,7 +91,7 @@ impl<'a> TreeBuilder<'a> {
 		Ok(if let Some(ix) = self.find_index(i).await? {
 			Node::IndexedField(i.to_owned(), ix)
 		} else {
-			Node::NonIndexedField
+			Node::NonIndexedField(i.to_owned())
 		})
 	}
 
@@ -191,7 +191,7 @@ pub(super) enum Node {
 		exp: Expression,
 	},
 	IndexedField(Idiom, DefineIndexStatement),
-	NonIndexedField,
+	NonIndexedField(Idiom),
 	Scalar(Value),
 	Unsupported,
 }
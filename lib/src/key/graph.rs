@@ -204,4 +204,30 @@ mod tests {
 		let dec = Graph::decode(&enc).unwrap();
 		assert_eq!(val, dec);
 	}
+
+	#[test]
+	fn key_malformed() {
+		use super::*;
+		use crate::sql::test::Parse;
+		let fk = Thing::parse("other:test");
+		#[rustfmt::skip]
+		let val = Graph::new(
+			"testns",
+			"testdb",
+			"testtb",
+			"testid".into(),
+			Dir::Out,
+			&fk,
+		);
+		let enc = Graph::encode(&val).unwrap();
+		// Every truncation of a valid key must be rejected
+		for i in 0..enc.len() {
+			assert!(Graph::decode(&enc[..i]).is_err(), "truncated at {i}");
+		}
+		// An unknown edge direction must be rejected
+		let mut bad = enc.clone();
+		let pos = b"/*testns\0*testdb\0*testtb\x00~\0\0\0\x01testid\0\0\0\0".len();
+		bad[pos] = 0x09;
+		assert!(Graph::decode(&bad).is_err());
+	}
 }
@@ -0,0 +1,56 @@
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Hc<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	pub tb: &'a str,
+	_d: u8,
+	_e: u8,
+	_f: u8,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tb: &'a str) -> Hc<'a> {
+	Hc::new(ns, db, tb)
+}
+
+impl<'a> Hc<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tb: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'*',
+			tb,
+			_d: b'!',
+			_e: b'h',
+			_f: b'c',
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Hc::new(
+			"testns",
+			"testdb",
+			"testtb",
+		);
+		let enc = Hc::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0*testtb\0!hc");
+
+		let dec = Hc::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
@@ -4,6 +4,11 @@
 ///
 /// HB              /!hb{ts}/{nd}
 ///
+/// RE              /!re{ts}{ns}{db}{tb}{id}
+///
+/// TE              /!te{ts}{key}
+/// TL              /!tl{key}
+///
 /// ND              /!nd{nd}
 /// NQ              /!nd{nd}*{ns}*{db}!lq{lq}
 ///
@@ -21,6 +26,8 @@
 /// DT              /*{ns}*{db}!dt{tk}
 /// PA              /*{ns}*{db}!pa{pa}
 /// SC              /*{ns}*{db}!sc{sc}
+/// SN              /*{ns}*{db}!sn{sq}
+/// SQ              /*{ns}*{db}!sq{sq}
 /// TB              /*{ns}*{db}!tb{tb}
 ///
 /// Scope           /*{ns}*{db}±{sc}
@@ -32,8 +39,10 @@
 /// EV              /*{ns}*{db}*{tb}!ev{ev}
 /// FD              /*{ns}*{db}*{tb}!fd{fd}
 /// FT              /*{ns}*{db}*{tb}!ft{ft}
+/// HC              /*{ns}*{db}*{tb}!hc
 /// IX              /*{ns}*{db}*{tb}!ix{ix}
 /// LV              /*{ns}*{db}*{tb}!lv{lv}
+/// RX              /*{ns}*{db}*{tb}!rx{id}
 ///
 /// Thing           /*{ns}*{db}*{tb}*{id}
 ///
@@ -77,6 +86,7 @@ pub mod fd; // Stores a DEFINE FIELD config definition
 pub mod ft; // Stores a DEFINE TABLE AS config definition
 pub mod graph; // Stores a graph edge pointer
 pub mod hb; // Stores a heartbeat per registered cluster node
+pub mod hc; // Stores a sketch of the records in a table
 pub mod index; // Stores an index entry
 pub mod ix; // Stores a DEFINE INDEX config definition
 pub mod kv; // Stores the key prefix for all keys
@@ -87,12 +97,18 @@ pub mod nl; // Stores a DEFINE LOGIN ON NAMESPACE config definition
 pub mod ns; // Stores a DEFINE NAMESPACE config definition
 pub mod nt; // Stores a DEFINE TOKEN ON NAMESPACE config definition
 pub mod pa; // Stores a DEFINE PARAM config definition
+pub mod re; // Stores the records in tables with an expiry in order of expiry
+pub mod rx; // Stores the expiry time of a record in a table with an expiry
 pub mod sc; // Stores a DEFINE SCOPE config definition
 pub mod scope; // Stores the key prefix for all keys under a scope
+pub mod sn; // Stores the current value of a DEFINE SEQUENCE sequence
+pub mod sq; // Stores a DEFINE SEQUENCE config definition
 pub mod st; // Stores a DEFINE TOKEN ON SCOPE config definition
 pub mod table; // Stores the key prefix for all keys under a table
 pub mod tb; // Stores a DEFINE TABLE config definition
+pub mod te; // Stores the keys with a time-to-live in order of expiry
 pub mod thing; // Stores a record id
+pub mod tl; // Stores the expiry time of a key with a time-to-live
 
 const CHAR_PATH: u8 = 0xb1; // ±
 const CHAR_INDEX: u8 = 0xa4; // ¤
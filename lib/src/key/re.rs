@@ -0,0 +1,82 @@
+//! Stores a record in a table with an expiry, ordered by the time at which it expires
+use crate::sql::id::Id;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Re<'a> {
+	__: u8,
+	_a: u8,
+	_b: u8,
+	_c: u8,
+	pub ts: u64,
+	pub ns: &'a str,
+	pub db: &'a str,
+	pub tb: &'a str,
+	pub id: Id,
+}
+
+pub fn new<'a>(ts: u64, ns: &'a str, db: &'a str, tb: &'a str, id: &Id) -> Re<'a> {
+	Re::new(ts, ns, db, tb, id.to_owned())
+}
+
+pub fn prefix() -> Vec<u8> {
+	let mut k = super::kv::new().encode().unwrap();
+	k.extend_from_slice(&[b'!', b'r', b'e']);
+	k
+}
+
+/// The end of the range of records which expire at or before the specified time
+pub fn suffix(ts: u64) -> Vec<u8> {
+	let mut k = prefix();
+	k.extend_from_slice(&ts.saturating_add(1).to_be_bytes());
+	k
+}
+
+impl<'a> Re<'a> {
+	pub fn new(ts: u64, ns: &'a str, db: &'a str, tb: &'a str, id: Id) -> Self {
+		Self {
+			__: b'/',
+			_a: b'!',
+			_b: b'r',
+			_c: b'e',
+			ts,
+			ns,
+			db,
+			tb,
+			id,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Re::new(
+			123,
+			"testns",
+			"testdb",
+			"testtb",
+			"testid".into(),
+		);
+		let enc = Re::encode(&val).unwrap();
+		assert_eq!(
+			enc,
+			b"/!re\x00\x00\x00\x00\x00\x00\x00\x7btestns\0testdb\0testtb\0\0\0\0\x01testid\0"
+		);
+
+		let dec = Re::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+
+	#[test]
+	fn suffix() {
+		use super::*;
+		let enc = Re::new(123, "testns", "testdb", "testtb", "testid".into()).encode().unwrap();
+		assert!(prefix() <= enc && enc < super::suffix(123));
+		assert!(super::suffix(122) <= enc);
+	}
+}
@@ -0,0 +1,81 @@
+//! Stores the time at which a record in a table with an expiry expires
+use crate::sql::id::Id;
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Rx<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	pub tb: &'a str,
+	_d: u8,
+	_e: u8,
+	_f: u8,
+	pub id: Id,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, tb: &'a str, id: &Id) -> Rx<'a> {
+	Rx::new(ns, db, tb, id.to_owned())
+}
+
+/// Encode the time, in milliseconds since the epoch, at which a record expires
+pub fn encode(ts: u64) -> Vec<u8> {
+	ts.to_be_bytes().to_vec()
+}
+
+/// Decode the time, in milliseconds since the epoch, at which a record expires
+pub fn decode(val: &[u8]) -> u64 {
+	match val.try_into() {
+		Ok(v) => u64::from_be_bytes(v),
+		Err(_) => 0,
+	}
+}
+
+impl<'a> Rx<'a> {
+	pub fn new(ns: &'a str, db: &'a str, tb: &'a str, id: Id) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'*',
+			tb,
+			_d: b'!',
+			_e: b'r',
+			_f: b'x',
+			id,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Rx::new(
+			"testns",
+			"testdb",
+			"testtb",
+			"testid".into(),
+		);
+		let enc = Rx::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0*testtb\0!rx\0\0\0\x01testid\0");
+
+		let dec = Rx::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+
+	#[test]
+	fn val() {
+		use super::*;
+		assert_eq!(decode(&encode(123)), 123);
+		assert_eq!(decode(b"invalid"), 0);
+	}
+}
@@ -0,0 +1,54 @@
+use derive::Key;
+use serde::{Deserialize, Serialize};
+
+#[derive(Clone, Debug, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Key)]
+pub struct Sn<'a> {
+	__: u8,
+	_a: u8,
+	pub ns: &'a str,
+	_b: u8,
+	pub db: &'a str,
+	_c: u8,
+	_d: u8,
+	_e: u8,
+	pub sq: &'a str,
+}
+
+pub fn new<'a>(ns: &'a str, db: &'a str, sq: &'a str) -> Sn<'a> {
+	Sn::new(ns, db, sq)
+}
+
+impl<'a> Sn<'a> {
+	pub fn new(ns: &'a str, db: &'a str, sq: &'a str) -> Self {
+		Self {
+			__: b'/',
+			_a: b'*',
+			ns,
+			_b: b'*',
+			db,
+			_c: b'!',
+			_d: b's',
+			_e: b'n',
+			sq,
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Sn::new(
+			"testns",
+			"testdb",
+			"testsq",
+		);
+		let enc = Sn::encode(&val).unwrap();
+		assert_eq!(enc, b"/*testns\0*testdb\0!sntestsq\0");
+
+		let dec = Sn::decode(&enc).unwrap();
+		assert_eq!(val, dec);
+	}
+}
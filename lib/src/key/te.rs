@@ -0,0 +1,52 @@
+//! Stores a key with a time-to-live, ordered by the time at which it expires
+//!
+//! The expiring key is appended as raw bytes after the big-endian expiry
+//! time, so that the keys which have expired can be found with a range scan.
+use crate::kvs::Key;
+
+/// The prefix of all ordered expiring keys
+pub const PREFIX: &[u8] = b"/!te";
+
+pub fn new(ts: u64, key: &[u8]) -> Key {
+	let mut k = PREFIX.to_vec();
+	k.extend_from_slice(&ts.to_be_bytes());
+	k.extend_from_slice(key);
+	k
+}
+
+pub fn prefix() -> Key {
+	PREFIX.to_vec()
+}
+
+/// The end of the range of keys which expire at or before the specified time
+pub fn suffix(ts: u64) -> Key {
+	let mut k = PREFIX.to_vec();
+	k.extend_from_slice(&ts.saturating_add(1).to_be_bytes());
+	k
+}
+
+/// Decode the expiry time and the expiring key
+pub fn decode(k: &[u8]) -> Option<(u64, Key)> {
+	let k = k.strip_prefix(PREFIX)?;
+	let ts = u64::from_be_bytes(k.get(..8)?.try_into().ok()?);
+	Some((ts, k[8..].to_vec()))
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		let enc = new(123, b"test");
+		assert_eq!(enc, b"/!te\x00\x00\x00\x00\x00\x00\x00\x7btest");
+		assert_eq!(decode(&enc), Some((123, b"test".to_vec())));
+		assert_eq!(decode(b"/!te\x00"), None);
+	}
+
+	#[test]
+	fn suffix() {
+		use super::*;
+		assert!(prefix() <= new(123, &[0xff; 8]) && new(123, &[0xff; 8]) < super::suffix(123));
+		assert!(super::suffix(122) <= new(123, b""));
+	}
+}
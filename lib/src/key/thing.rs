@@ -66,6 +66,37 @@ mod tests {
 		assert_eq!(val, dec);
 	}
 	#[test]
+	fn key_malformed() {
+		use super::*;
+		#[rustfmt::skip]
+		let val = Thing::new(
+			"testns",
+			"testdb",
+			"testtb",
+			"testid".into(),
+		);
+		let enc = Thing::encode(&val).unwrap();
+		// Every truncation of a valid key must be rejected
+		for i in 0..enc.len() {
+			assert!(Thing::decode(&enc[..i]).is_err(), "truncated at {i}");
+		}
+		// Corrupted keys must be rejected without panicking
+		let corpus: &[&[u8]] = &[
+			b"",
+			b"/",
+			b"/*testns\0*testdb\0*testtb\0*",
+			b"/*testns\0*testdb\0*testtb\0*\0\0\0",
+			b"/*testns\0*testdb\0*testtb\0*\0\0\0\x09testid\0",
+			b"/*testns\0*testdb\0*testtb\0*\0\0\0\x01testid",
+			b"/*testns\0*testdb\0*testtb\0*\0\0\0\x00\x80",
+			b"/*testns\0*testdb\0*testtb\0*\0\0\0\x02\0\0\0\x04test\0",
+			b"/*testns\0*testdb\0*testtb\0*\0\0\0\x02\0\0\0\xff",
+		];
+		for key in corpus {
+			assert!(Thing::decode(key).is_err(), "decoded {key:?}");
+		}
+	}
+	#[test]
 	fn key_complex() {
 		use super::*;
 		//
@@ -0,0 +1,54 @@
+//! Stores the expiry time of a key which has a time-to-live
+//!
+//! The expiring key is appended as raw bytes, rather than being encoded,
+//! so that the expiry times of a range of keys are stored in the same order.
+use crate::kvs::Key;
+
+/// The prefix of all key expiry times
+pub const PREFIX: &[u8] = b"/!tl";
+
+pub fn new(key: &[u8]) -> Key {
+	let mut k = PREFIX.to_vec();
+	k.extend_from_slice(key);
+	k
+}
+
+pub fn prefix() -> Key {
+	PREFIX.to_vec()
+}
+
+pub fn suffix() -> Key {
+	b"/!tm".to_vec()
+}
+
+/// Encode the time, in milliseconds since the epoch, at which a key expires
+pub fn encode(ts: u64) -> Vec<u8> {
+	ts.to_be_bytes().to_vec()
+}
+
+/// Decode the time, in milliseconds since the epoch, at which a key expires
+pub fn decode(val: &[u8]) -> u64 {
+	match val.try_into() {
+		Ok(v) => u64::from_be_bytes(v),
+		Err(_) => 0,
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	#[test]
+	fn key() {
+		use super::*;
+		let enc = new(b"test");
+		assert_eq!(enc, b"/!tltest");
+		assert!(prefix() <= enc && enc < suffix());
+		assert!(prefix() <= new(&[0xff; 8]) && new(&[0xff; 8]) < suffix());
+	}
+
+	#[test]
+	fn val() {
+		use super::*;
+		assert_eq!(decode(&encode(123)), 123);
+		assert_eq!(decode(b"invalid"), 0);
+	}
+}
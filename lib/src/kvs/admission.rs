@@ -0,0 +1,136 @@
+use crate::cnf::COMMIT_LATENCY_TARGET;
+use crate::err::Error;
+use std::sync::atomic::{AtomicU64, AtomicUsize, Ordering};
+use std::sync::Arc;
+use std::time::Duration;
+
+/// The priority of a write transaction, which determines how early it is
+/// rejected when the node is overloaded.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq, Ord, PartialOrd, Hash)]
+pub enum Priority {
+	/// Rejected once half of the write transactions are in use, or once
+	/// commits take longer than the target commit latency
+	Low,
+	/// Rejected once all of the write transactions are in use
+	#[default]
+	Normal,
+	/// Never rejected, used for the upkeep of the datastore itself
+	High,
+}
+
+/// Limits how many write transactions can be open at the same time,
+/// so that an overloaded node rejects new writes straight away, instead
+/// of queueing them until every request times out.
+///
+/// Both the number of open write transactions and the recent commit
+/// latency are monitored, so that lower priority writes are shed first.
+pub(super) struct Admission {
+	max: usize,
+	load: Arc<Load>,
+}
+
+// The load which is shared with every open write transaction
+#[derive(Default)]
+struct Load {
+	// The number of open write transactions
+	open: AtomicUsize,
+	// The moving average of the commit latency, in microseconds
+	latency: AtomicU64,
+}
+
+/// Counts towards the open write transactions until it is dropped.
+pub(super) struct Permit(Arc<Load>);
+
+impl Drop for Permit {
+	fn drop(&mut self) {
+		self.0.open.fetch_sub(1, Ordering::SeqCst);
+	}
+}
+
+impl Permit {
+	/// Record how long the transaction took to commit
+	pub(super) fn observe(&self, latency: Duration) {
+		let sample = latency.as_micros().min(u64::MAX as u128) as u64;
+		// Weight the new sample at an eighth of the average
+		let _ = self.0.latency.fetch_update(Ordering::SeqCst, Ordering::SeqCst, |avg| {
+			Some(avg - avg / 8 + sample / 8)
+		});
+	}
+}
+
+impl Admission {
+	pub(super) fn new(max: usize) -> Admission {
+		Admission {
+			max,
+			load: Arc::new(Load::default()),
+		}
+	}
+
+	/// Admit a new write transaction, if the node is not too overloaded for its priority
+	pub(super) fn admit(&self, priority: Priority) -> Result<Permit, Error> {
+		let max = match priority {
+			Priority::Low if self.latency() > *COMMIT_LATENCY_TARGET => 0,
+			Priority::Low => self.max / 2,
+			Priority::Normal => self.max,
+			Priority::High => usize::MAX,
+		};
+		if self.load.open.fetch_add(1, Ordering::SeqCst) >= max {
+			self.load.open.fetch_sub(1, Ordering::SeqCst);
+			return Err(Error::TxOverloaded);
+		}
+		Ok(Permit(self.load.clone()))
+	}
+
+	// The moving average of the commit latency
+	fn latency(&self) -> Duration {
+		Duration::from_micros(self.load.latency.load(Ordering::SeqCst))
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn rejects_over_limit() {
+		let a = Admission::new(2);
+		let p1 = a.admit(Priority::Normal).unwrap();
+		let p2 = a.admit(Priority::Normal).unwrap();
+		assert!(matches!(a.admit(Priority::Normal), Err(Error::TxOverloaded)));
+		drop(p1);
+		let p3 = a.admit(Priority::Normal).unwrap();
+		assert!(matches!(a.admit(Priority::Normal), Err(Error::TxOverloaded)));
+		drop(p2);
+		drop(p3);
+		assert_eq!(a.load.open.load(Ordering::SeqCst), 0);
+	}
+
+	#[test]
+	fn sheds_by_priority() {
+		let a = Admission::new(4);
+		let p1 = a.admit(Priority::Low).unwrap();
+		let p2 = a.admit(Priority::Low).unwrap();
+		assert!(matches!(a.admit(Priority::Low), Err(Error::TxOverloaded)));
+		let p3 = a.admit(Priority::Normal).unwrap();
+		let p4 = a.admit(Priority::Normal).unwrap();
+		assert!(matches!(a.admit(Priority::Normal), Err(Error::TxOverloaded)));
+		let p5 = a.admit(Priority::High).unwrap();
+		drop((p1, p2, p3, p4, p5));
+		assert_eq!(a.load.open.load(Ordering::SeqCst), 0);
+	}
+
+	#[test]
+	fn sheds_on_commit_latency() {
+		let a = Admission::new(4);
+		let p = a.admit(Priority::Normal).unwrap();
+		for _ in 0..64 {
+			p.observe(*COMMIT_LATENCY_TARGET * 2);
+		}
+		assert!(matches!(a.admit(Priority::Low), Err(Error::TxOverloaded)));
+		assert!(a.admit(Priority::Normal).is_ok());
+		for _ in 0..64 {
+			p.observe(Duration::ZERO);
+		}
+		assert!(a.admit(Priority::Low).is_ok());
+	}
+}
@@ -0,0 +1,201 @@
+//! The engine-agnostic backup format.
+//!
+//! A backup starts with a header containing the magic bytes and the format version. It is
+//! followed by blocks of key-value pairs. Each block starts with a big-endian `u32` count of
+//! its records, and each record is written as a big-endian `u32` key length, the key, a
+//! big-endian `u32` value length, and the value. Each block ends with the SHA-256 checksum
+//! of everything before it in the backup. A block with no records marks the end of the backup.
+
+use super::Datastore;
+use super::Key;
+use super::Transaction;
+use super::Val;
+use crate::err::Error;
+use channel::Sender;
+use futures::io::AsyncRead;
+use futures::io::AsyncReadExt;
+use sha2::{Digest, Sha256};
+use std::ops::Range;
+
+/// The bytes which every backup starts with
+const MAGIC: &[u8] = b"SURREALDB-BACKUP";
+/// The version of the backup format
+const VERSION: u8 = 2;
+/// The size of the blocks which are sent to the backup channel
+const CHUNK: usize = 64 * 1024;
+/// The number of keys which are deleted in each transaction
+const BATCH: u32 = 1000;
+
+/// Write every key-value pair in the datastore to the channel
+///
+/// The keys are read from a single snapshot, so that the backup is a
+/// consistent copy of the datastore. Storage engines which limit the
+/// lifetime of a transaction fail the backup once it is exceeded.
+pub(super) async fn write(ds: &Datastore, chn: Sender<Vec<u8>>) -> Result<(), Error> {
+	let mut hasher = Sha256::new();
+	// Write the header
+	let mut buf = MAGIC.to_vec();
+	buf.push(VERSION);
+	hasher.update(&buf);
+	// Write the records
+	let mut txn = ds.transaction(false, false).await?;
+	let res = records(&mut txn, ds.keyspace(), &chn, &mut buf, &mut hasher).await;
+	txn.cancel().await?;
+	res?;
+	// Write the end marker
+	block(&mut buf, &mut hasher, 0, &mut Vec::new());
+	chn.send(buf).await?;
+	Ok(())
+}
+
+/// Write the key-value pairs in a range as blocks of records
+async fn records(
+	txn: &mut Transaction,
+	rng: Range<Key>,
+	chn: &Sender<Vec<u8>>,
+	buf: &mut Vec<u8>,
+	hasher: &mut Sha256,
+) -> Result<(), Error> {
+	let mut cur = txn.cursor(rng);
+	let mut blk = Vec::new();
+	let mut count = 0;
+	while let Some((k, v)) = cur.next().await? {
+		blk.extend((k.len() as u32).to_be_bytes());
+		blk.extend(k);
+		blk.extend((v.len() as u32).to_be_bytes());
+		blk.extend(v);
+		count += 1;
+		if blk.len() >= CHUNK {
+			block(buf, hasher, count, &mut blk);
+			chn.send(std::mem::take(buf)).await?;
+			count = 0;
+		}
+	}
+	// Write the remaining records
+	if count > 0 {
+		block(buf, hasher, count, &mut blk);
+	}
+	Ok(())
+}
+
+/// Read every key-value pair from the backup into the datastore
+///
+/// Each block is written in its own transaction, once its checksum
+/// has been verified, so a corrupted block is never written.
+pub(super) async fn read<R>(ds: &Datastore, dump: &mut R) -> Result<(), Error>
+where
+	R: AsyncRead + Unpin,
+{
+	let mut hasher = Sha256::new();
+	// Check the header
+	let head = bytes(dump, &mut hasher, MAGIC.len() + 1).await?;
+	if head[..MAGIC.len()] != *MAGIC || head[MAGIC.len()] != VERSION {
+		return Err(Error::InvalidBackup);
+	}
+	// Read the blocks
+	loop {
+		// Read the records in the block
+		let count = length(dump, &mut hasher).await?;
+		let mut records: Vec<(Key, Val)> = Vec::new();
+		for _ in 0..count {
+			let len = length(dump, &mut hasher).await? as usize;
+			let k = bytes(dump, &mut hasher, len).await?;
+			let len = length(dump, &mut hasher).await? as usize;
+			let v = bytes(dump, &mut hasher, len).await?;
+			records.push((k, v));
+		}
+		// Check the checksum of the block
+		checksum(dump, &mut hasher).await?;
+		// Check if this is the end of the backup
+		if count == 0 {
+			break;
+		}
+		// Write the records in the block
+		let mut txn = ds.transaction(true, false).await?;
+		for (k, v) in records {
+			if let Err(e) = txn.set(k, v).await {
+				txn.cancel().await?;
+				return Err(e);
+			}
+		}
+		txn.commit().await?;
+	}
+	Ok(())
+}
+
+/// Check if there are no keys in the datastore
+pub(super) async fn empty(ds: &Datastore) -> Result<bool, Error> {
+	let mut txn = ds.transaction(false, false).await?;
+	let res = txn.getr(ds.keyspace(), 1).await;
+	txn.cancel().await?;
+	Ok(res?.is_empty())
+}
+
+/// Remove every key in the datastore, in batches
+pub(super) async fn clear(ds: &Datastore) -> Result<(), Error> {
+	loop {
+		let mut txn = ds.transaction(true, false).await?;
+		let res = match txn.getr(ds.keyspace(), BATCH).await {
+			Ok(res) => res,
+			Err(e) => {
+				txn.cancel().await?;
+				return Err(e);
+			}
+		};
+		for (k, _) in res.iter() {
+			txn.del(k.clone()).await?;
+		}
+		txn.commit().await?;
+		if res.len() < BATCH as usize {
+			return Ok(());
+		}
+	}
+}
+
+/// Write a block of records, followed by the checksum of the backup so far
+fn block(buf: &mut Vec<u8>, hasher: &mut Sha256, count: u32, records: &mut Vec<u8>) {
+	let beg = buf.len();
+	buf.extend(count.to_be_bytes());
+	buf.append(records);
+	hasher.update(&buf[beg..]);
+	let sum = hasher.clone().finalize();
+	hasher.update(sum);
+	buf.extend(sum);
+}
+
+async fn checksum<R>(dump: &mut R, hasher: &mut Sha256) -> Result<(), Error>
+where
+	R: AsyncRead + Unpin,
+{
+	let mut sum = [0; 32];
+	dump.read_exact(&mut sum).await.map_err(|_| Error::InvalidBackup)?;
+	if hasher.clone().finalize()[..] != sum {
+		return Err(Error::InvalidBackup);
+	}
+	hasher.update(sum);
+	Ok(())
+}
+
+async fn length<R>(dump: &mut R, hasher: &mut Sha256) -> Result<u32, Error>
+where
+	R: AsyncRead + Unpin,
+{
+	let mut len = [0; 4];
+	dump.read_exact(&mut len).await.map_err(|_| Error::InvalidBackup)?;
+	hasher.update(len);
+	Ok(u32::from_be_bytes(len))
+}
+
+async fn bytes<R>(dump: &mut R, hasher: &mut Sha256, len: usize) -> Result<Vec<u8>, Error>
+where
+	R: AsyncRead + Unpin,
+{
+	// Only allocate as much as has actually been read
+	let mut buf = Vec::new();
+	dump.take(len as u64).read_to_end(&mut buf).await.map_err(|_| Error::InvalidBackup)?;
+	if buf.len() != len {
+		return Err(Error::InvalidBackup);
+	}
+	hasher.update(&buf);
+	Ok(buf)
+}
@@ -0,0 +1,17 @@
+/// Describes the features which are natively supported by a storage engine.
+///
+/// Higher layers can use this to take advantage of native features when
+/// they are available, and fall back to an emulated implementation built
+/// on the basic key-value operations when they are not.
+#[derive(Clone, Copy, Debug, Default, Eq, PartialEq)]
+#[non_exhaustive]
+pub struct Capabilities {
+	/// Keys can expire automatically after a time-to-live
+	pub ttl: bool,
+	/// Ranges of keys can be deleted without reading them first
+	pub range_delete: bool,
+	/// Transactions read from a consistent snapshot of the data
+	pub snapshots: bool,
+	/// Commits are given a monotonically increasing versionstamp by the engine
+	pub versionstamps: bool,
+}
@@ -0,0 +1,64 @@
+use super::Key;
+use super::Transaction;
+use super::Val;
+use crate::err::Error;
+use std::ops::Range;
+use std::vec::IntoIter;
+
+/// The number of key-value pairs which are fetched in each batch
+const BATCH: u32 = 1000;
+
+/// Walks over a range of keys in batches, so that large ranges can be
+/// processed without loading every key-value pair into memory at once.
+pub struct Cursor<'a> {
+	tx: &'a mut Transaction,
+	// The key to start the next batch from
+	beg: Key,
+	// The end of the range, exclusive
+	end: Key,
+	// The remaining pairs in the current batch
+	batch: IntoIter<(Key, Val)>,
+	// Whether the whole range has been fetched
+	done: bool,
+}
+
+impl<'a> Cursor<'a> {
+	pub(super) fn new(tx: &'a mut Transaction, rng: Range<Key>) -> Cursor<'a> {
+		Cursor {
+			tx,
+			done: rng.start >= rng.end,
+			beg: rng.start,
+			end: rng.end,
+			batch: Vec::new().into_iter(),
+		}
+	}
+
+	/// Fetch the next key-value pair in the range, or `None` once the range is exhausted.
+	pub async fn next(&mut self) -> Result<Option<(Key, Val)>, Error> {
+		loop {
+			// Return the next pair from the current batch
+			for (k, v) in self.batch.by_ref() {
+				// Ignore the key if it has expired
+				if !self.tx.expired(&k).await? {
+					return Ok(Some((k, v)));
+				}
+			}
+			// Exit when settled
+			if self.done {
+				return Ok(None);
+			}
+			// Get the next batch
+			let res = self.tx.scan(self.beg.clone()..self.end.clone(), BATCH).await?;
+			// Ready the next batch
+			match res.last() {
+				Some((k, _)) if res.len() as u32 == BATCH => {
+					self.beg = k.clone();
+					self.beg.push(0x00);
+					self.done = self.beg >= self.end;
+				}
+				_ => self.done = true,
+			}
+			self.batch = res.into_iter();
+		}
+	}
+}
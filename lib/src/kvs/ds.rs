@@ -1,30 +1,58 @@
+use crate::cnf::MAX_STATEMENT_RETRIES;
+use crate::cnf::SEQUENCE_BATCH_SIZE;
 use crate::ctx::Context;
 use crate::dbs::cl::Timestamp;
+use crate::dbs::Accounting;
 use crate::dbs::Attach;
+use crate::dbs::Auth;
+use crate::dbs::Counter;
+use crate::dbs::CustomFunction;
 use crate::dbs::Executor;
+use crate::dbs::Hook;
 use crate::dbs::Notification;
 use crate::dbs::Options;
+use crate::dbs::QueryCapabilities;
 use crate::dbs::Response;
 use crate::dbs::Session;
+use crate::dbs::Usage;
 use crate::dbs::Variables;
 use crate::err::Error;
+use crate::idx::planner::advisor::{Advisor, Suggestion};
 use crate::key::hb::Hb;
 use crate::key::lq;
 use crate::key::lv::Lv;
 use crate::sql;
+use crate::sql::statements::DeleteStatement;
+use crate::sql::Idiom;
 use crate::sql::Query;
+use crate::sql::Thing;
 use crate::sql::Value;
+use crate::sql::Values;
+use crate::vs::HybridLogicalClock;
 use channel::Receiver;
 use channel::Sender;
+use futures::io::AsyncRead;
 use futures::lock::Mutex;
+use std::collections::BTreeSet;
+use std::collections::HashMap;
 use std::fmt;
+use std::ops::Range;
 use std::sync::Arc;
 use std::time::Duration;
 use tracing::instrument;
 use tracing::trace;
 use uuid::Uuid;
 
+use super::admission::Admission;
+use super::sequences::Sequences;
 use super::tx::Transaction;
+use super::Capabilities;
+use super::Key;
+use super::Priority;
+use super::Val;
+
+/// The number of expired keys which are removed in each transaction
+const EXPIRY_BATCH_SIZE: u32 = 1000;
 
 /// Used for cluster logic to move LQ data to LQ cleanup code
 /// Not a stored struct; Used only in this module
@@ -46,12 +74,32 @@ pub struct Datastore {
 	id: Uuid,
 	// Whether this datastore runs in strict mode by default
 	strict: bool,
+	// Whether keys can be written with a time-to-live
+	key_expiry: bool,
 	// The maximum duration timeout for running multiple statements in a query
 	query_timeout: Option<Duration>,
 	// The maximum duration timeout for running multiple statements in a transaction
 	transaction_timeout: Option<Duration>,
 	// Whether this datastore enables live query notifications to subscribers
 	notification_channel: Option<(Sender<Notification>, Receiver<Notification>)>,
+	// The hook which is run whenever a record is written
+	hook: Option<Arc<dyn Hook>>,
+	// The functions which are registered by an embedding application
+	functions: Arc<HashMap<String, Arc<CustomFunction>>>,
+	// The functionality which queries are allowed to use
+	query_capabilities: Arc<QueryCapabilities>,
+	// Sheds write transactions when the datastore is overloaded
+	admission: Option<Admission>,
+	// The clock which the versionstamps of transactions are taken from
+	hlc: Arc<HybridLogicalClock>,
+	// The named snapshots which have been saved for an in-memory datastore
+	states: Mutex<HashMap<String, Vec<(Key, Val)>>>,
+	// The values which have been allocated for each sequence
+	sequences: Arc<Sequences>,
+	// The resources which have been used by each namespace
+	accounting: Accounting,
+	// The full table scans which could have used an index
+	advisor: Advisor,
 }
 
 #[allow(clippy::large_enum_variant)]
@@ -245,30 +293,118 @@ impl Datastore {
 			id: node_id,
 			inner,
 			strict: false,
+			key_expiry: false,
 			query_timeout: None,
 			transaction_timeout: None,
 			notification_channel: None,
+			hook: None,
+			functions: Arc::new(HashMap::new()),
+			query_capabilities: Arc::new(QueryCapabilities::default()),
+			admission: None,
+			hlc: Arc::new(HybridLogicalClock::new()),
+			states: Mutex::new(HashMap::new()),
+			sequences: Arc::new(Sequences::default()),
+			accounting: Accounting::default(),
+			advisor: Advisor::default(),
 		})
 	}
 
+	/// Get the features which are natively supported by the storage engine
+	pub fn capabilities(&self) -> Capabilities {
+		match &self.inner {
+			#[cfg(feature = "kv-mem")]
+			Inner::Mem(_) => Capabilities {
+				snapshots: true,
+				..Default::default()
+			},
+			#[cfg(feature = "kv-rocksdb")]
+			Inner::RocksDB(_) => Capabilities {
+				snapshots: true,
+				..Default::default()
+			},
+			#[cfg(feature = "kv-speedb")]
+			Inner::SpeeDB(_) => Capabilities {
+				snapshots: true,
+				..Default::default()
+			},
+			#[cfg(feature = "kv-indxdb")]
+			Inner::IndxDB(_) => Capabilities::default(),
+			#[cfg(feature = "kv-tikv")]
+			Inner::TiKV(_) => Capabilities {
+				snapshots: true,
+				versionstamps: true,
+				..Default::default()
+			},
+			#[cfg(feature = "kv-fdb")]
+			Inner::FoundationDB(_) => Capabilities {
+				range_delete: true,
+				snapshots: true,
+				versionstamps: true,
+				..Default::default()
+			},
+			#[allow(unreachable_patterns)]
+			_ => unreachable!(),
+		}
+	}
+
 	/// Specify whether this Datastore should run in strict mode
 	pub fn with_strict_mode(mut self, strict: bool) -> Self {
 		self.strict = strict;
 		self
 	}
 
+	/// Specify whether keys can be written with a time-to-live
+	///
+	/// Once enabled, every read and write of a key also reads its expiry
+	/// time, so this is disabled by default, and [`Transaction::set_ttl`]
+	/// fails with `Error::KeyExpiryDisabled`.
+	pub fn with_key_expiry(mut self, enabled: bool) -> Self {
+		self.key_expiry = enabled;
+		self
+	}
+
 	/// Specify whether this datastore should enable live query notifications
 	pub fn with_notifications(mut self) -> Self {
 		self.notification_channel = Some(channel::bounded(100));
 		self
 	}
 
+	/// Specify a hook to run whenever a record is written to this datastore
+	pub fn with_hook(mut self, hook: Arc<dyn Hook>) -> Self {
+		self.hook = Some(hook);
+		self
+	}
+
+	/// Register a function which can be called from queries as `fn::name()`
+	///
+	/// Registered functions take precedence over any functions
+	/// with the same name created with a `DEFINE FUNCTION` statement.
+	pub fn with_function(mut self, name: &str, function: CustomFunction) -> Self {
+		Arc::make_mut(&mut self.functions).insert(name.to_owned(), Arc::new(function));
+		self
+	}
+
+	/// Specify the functionality which queries are allowed to use
+	pub fn with_query_capabilities(mut self, capabilities: QueryCapabilities) -> Self {
+		self.query_capabilities = Arc::new(capabilities);
+		self
+	}
+
 	/// Set a global query timeout for this Datastore
 	pub fn with_query_timeout(mut self, duration: Option<Duration>) -> Self {
 		self.query_timeout = duration;
 		self
 	}
 
+	/// Set the maximum number of write transactions which can be open at
+	/// once. Any further write transactions fail with `Error::TxOverloaded`.
+	/// Low priority write transactions are rejected once half of the limit
+	/// is in use, or whenever commits are slower than the target latency.
+	pub fn with_transaction_limit(mut self, limit: Option<usize>) -> Self {
+		self.admission = limit.map(Admission::new);
+		self
+	}
+
 	/// Set a global transaction timeout for this Datastore
 	pub fn with_transaction_timeout(mut self, duration: Option<Duration>) -> Self {
 		self.transaction_timeout = duration;
@@ -292,12 +428,12 @@ impl Datastore {
 	// Initialise bootstrap with artificial values, intended for testing
 	pub async fn bootstrap_full(&self, node_id: &Uuid) -> Result<(), Error> {
 		trace!("Bootstrapping {}", self.id);
-		let mut tx = self.transaction(true, false).await?;
+		let mut tx = self.transaction_with_priority(true, false, Priority::High).await?;
 		let now = tx.clock();
 		let archived = self.register_remove_and_archive(&mut tx, node_id, now).await?;
 		tx.commit().await?;
 
-		let mut tx = self.transaction(true, false).await?;
+		let mut tx = self.transaction_with_priority(true, false, Priority::High).await?;
 		self.remove_archived(&mut tx, archived).await?;
 		tx.commit().await
 	}
@@ -450,7 +586,7 @@ impl Datastore {
 	// that the node is alive.
 	// This is the preferred way of creating heartbeats inside the database, so try to use this.
 	pub async fn heartbeat(&self) -> Result<(), Error> {
-		let mut tx = self.transaction(true, false).await?;
+		let mut tx = self.transaction_with_priority(true, false, Priority::High).await?;
 		let timestamp = tx.clock();
 		self.heartbeat_full(&mut tx, timestamp, self.id).await?;
 		tx.commit().await
@@ -488,7 +624,25 @@ impl Datastore {
 	/// }
 	/// ```
 	pub async fn transaction(&self, write: bool, lock: bool) -> Result<Transaction, Error> {
+		self.transaction_with_priority(write, lock, Priority::Normal).await
+	}
+
+	/// Create a new transaction on this datastore with the specified priority
+	///
+	/// When a transaction limit is set, and the datastore is overloaded, write
+	/// transactions with a lower priority are rejected first.
+	pub async fn transaction_with_priority(
+		&self,
+		write: bool,
+		lock: bool,
+		priority: Priority,
+	) -> Result<Transaction, Error> {
 		#![allow(unused_variables)]
+		// Check that the node is not overloaded with writes
+		let permit = match &self.admission {
+			Some(admission) if write => Some(admission.admit(priority)?),
+			_ => None,
+		};
 		let inner = match &self.inner {
 			#[cfg(feature = "kv-mem")]
 			Inner::Mem(v) => {
@@ -528,9 +682,261 @@ impl Datastore {
 		Ok(Transaction {
 			inner,
 			cache: super::cache::Cache::default(),
+			guard: None,
+			capabilities: self.capabilities(),
+			permit,
+			hlc: self.hlc.clone(),
+			vs: None,
+			cf: crate::cf::Writer::default(),
+			ttl: self.key_expiry,
+			sequences: self.sequences.clone(),
+			restarts: BTreeSet::new(),
 		})
 	}
 
+	/// Remove the keys whose time-to-live has passed from the storage engine
+	///
+	/// Expired keys are never returned when fetching keys, but they remain
+	/// in the storage engine until they are removed by this function, which
+	/// should be run periodically. None of the storage engines expire keys
+	/// natively.
+	///
+	/// The records in tables which are defined with an EXPIRE clause are
+	/// deleted once they have not been written for that long, along with
+	/// their index entries and edges.
+	pub async fn expire(&self) -> Result<(), Error> {
+		while self.expire_records().await? == EXPIRY_BATCH_SIZE as usize {}
+		// No keys have a time-to-live unless key expiry is enabled
+		if !self.key_expiry {
+			return Ok(());
+		}
+		loop {
+			let mut tx = self.transaction(true, false).await?;
+			let n = match tx.expire(EXPIRY_BATCH_SIZE).await {
+				Ok(n) => {
+					tx.commit().await?;
+					n
+				}
+				Err(e) => {
+					tx.cancel().await?;
+					return Err(e);
+				}
+			};
+			if n < EXPIRY_BATCH_SIZE as usize {
+				return Ok(());
+			}
+		}
+	}
+
+	/// Delete a batch of records whose table expiry has passed, returning how many were found
+	async fn expire_records(&self) -> Result<usize, Error> {
+		// Start a new transaction
+		let txn = self.transaction_with_priority(true, false, Priority::High).await?;
+		let txn = Arc::new(Mutex::new(txn));
+		// Create a default context
+		let mut ctx = Context::default();
+		// Setup the notification channel
+		if let Some(channel) = &self.notification_channel {
+			ctx.add_notifications(Some(&channel.0));
+		}
+		// Setup the datastore write hook
+		ctx.add_hook(self.hook.as_ref());
+		// Setup the datastore reference
+		ctx.add_datastore(self);
+		// Delete the expired records
+		let res = self.expire_records_in(&ctx, &txn).await;
+		let mut run = txn.lock().await;
+		match res {
+			Ok(n) => {
+				run.commit().await?;
+				Ok(n)
+			}
+			Err(e) => {
+				run.cancel().await?;
+				Err(e)
+			}
+		}
+	}
+
+	async fn expire_records_in(
+		&self,
+		ctx: &Context<'_>,
+		txn: &crate::dbs::Transaction,
+	) -> Result<usize, Error> {
+		let now = chrono::Utc::now().timestamp_millis() as u64;
+		let beg = crate::key::re::prefix();
+		let end = crate::key::re::suffix(now);
+		let res = txn.lock().await.getr(beg..end, EXPIRY_BATCH_SIZE).await?;
+		for (k, _) in res.iter() {
+			let re = crate::key::re::Re::decode(k)?;
+			// Remove the record from the expiry queue
+			txn.lock().await.del(k.clone()).await?;
+			// Only delete the record if it was not written again since
+			let key = crate::key::rx::new(re.ns, re.db, re.tb, &re.id);
+			match txn.lock().await.get(key).await? {
+				Some(v) if crate::key::rx::decode(&v) == re.ts => (),
+				_ => continue,
+			}
+			// Only delete the record if the table still has an expiry
+			match txn.lock().await.get_tb(re.ns, re.db, re.tb).await {
+				Ok(tb) if tb.expire.is_some() => (),
+				_ => continue,
+			}
+			// Delete the record as the datastore itself
+			let opt = Options::default()
+				.with_id(self.id)
+				.with_ns(Some(re.ns.into()))
+				.with_db(Some(re.db.into()))
+				.with_auth(Arc::new(Auth::Kv))
+				.with_strict(self.strict);
+			let stm = DeleteStatement {
+				what: Values(vec![Value::from(Thing::from((re.tb, re.id.clone())))]),
+				..DeleteStatement::default()
+			};
+			stm.compute(ctx, &opt, txn, None).await?;
+		}
+		Ok(res.len())
+	}
+
+	/// Save the contents of an in-memory datastore as a named snapshot
+	///
+	/// This is intended for test suites which seed a datastore once, and then
+	/// reset it to the seeded state between test cases with [`Datastore::load_state`].
+	pub async fn save_state(&self, name: &str) -> Result<(), Error> {
+		// Check the storage engine
+		self.check_in_memory()?;
+		// Read all of the keys in the datastore
+		let mut txn = self.transaction(false, false).await?;
+		let data = txn.getr(self.keyspace(), u32::MAX).await?;
+		txn.cancel().await?;
+		// Store the named snapshot
+		self.states.lock().await.insert(name.to_owned(), data);
+		Ok(())
+	}
+
+	/// Reset an in-memory datastore to a named snapshot saved with [`Datastore::save_state`]
+	pub async fn load_state(&self, name: &str) -> Result<(), Error> {
+		// Check the storage engine
+		self.check_in_memory()?;
+		// Fetch the named snapshot
+		let states = self.states.lock().await;
+		let data = states.get(name).ok_or_else(|| Error::SnapshotNotFound {
+			value: name.to_owned(),
+		})?;
+		// Replace all of the keys in the datastore
+		let mut txn = self.transaction(true, false).await?;
+		txn.delr(self.keyspace(), u32::MAX).await?;
+		for (k, v) in data.iter() {
+			txn.set(k.clone(), v.clone()).await?;
+		}
+		txn.commit().await
+	}
+
+	// Named snapshots copy the whole keyspace, so are only supported in memory
+	fn check_in_memory(&self) -> Result<(), Error> {
+		match &self.inner {
+			#[cfg(feature = "kv-mem")]
+			Inner::Mem(_) => Ok(()),
+			#[allow(unreachable_patterns)]
+			_ => Err(Error::Unimplemented(String::from(
+				"Named snapshots are only supported by the in-memory storage engine",
+			))),
+		}
+	}
+
+	/// Get the next value of a sequence
+	///
+	/// Values are allocated in batches, in a transaction which is separate
+	/// from the one running the query, so that concurrent queries which use
+	/// the same sequence do not conflict with each other. Values which are
+	/// allocated, but not used, are lost when the datastore is closed.
+	///
+	/// In a dry run, or in a transaction which is shared by several
+	/// statements, the sequence is advanced within the query transaction
+	/// instead, so that nothing is committed separately, and so that a
+	/// sequence defined earlier in the same transaction can be used.
+	pub(crate) async fn next_sn(
+		&self,
+		ctx: &Context<'_>,
+		txn: &crate::dbs::Transaction,
+		ns: &str,
+		db: &str,
+		sq: &str,
+	) -> Result<i64, Error> {
+		// Check that the sequence is defined
+		txn.lock().await.get_sq(ns, db, sq).await?;
+		// Advance the sequence within the query transaction
+		if ctx.is_dry_run() || ctx.is_explicit() {
+			return txn.lock().await.next_sn(ns, db, sq).await;
+		}
+		// Engines which serialise write transactions would deadlock on
+		// a second transaction, and can never conflict, so the sequence
+		// is advanced within the current transaction instead
+		match &self.inner {
+			#[cfg(feature = "kv-mem")]
+			Inner::Mem(_) => return txn.lock().await.next_sn(ns, db, sq).await,
+			#[cfg(feature = "kv-indxdb")]
+			Inner::IndxDB(_) => return txn.lock().await.next_sn(ns, db, sq).await,
+			#[allow(unreachable_patterns)]
+			_ => (),
+		}
+		// Take the next value which is already allocated
+		let entry = self.sequences.get(&(ns.to_owned(), db.to_owned(), sq.to_owned()));
+		let mut cache = entry.lock().await;
+		if let Some(v) = cache.next() {
+			return Ok(v);
+		}
+		// Allocate a new batch of values
+		let mut retries = 0;
+		let mut val = loop {
+			let mut run = self.transaction(true, false).await?;
+			let res = match run.alloc_sn(ns, db, sq, *SEQUENCE_BATCH_SIZE).await {
+				Ok(val) => run.commit().await.map(|_| val),
+				Err(e) => {
+					run.cancel().await?;
+					Err(e)
+				}
+			};
+			match res {
+				Err(Error::TxRetryable) if retries < *MAX_STATEMENT_RETRIES => retries += 1,
+				res => break res?,
+			}
+		};
+		// Return the first allocated value
+		let next = val.next().unwrap_or(val.start);
+		*cache = val;
+		Ok(next)
+	}
+
+	/// Get the resources used by each namespace since this datastore was started
+	///
+	/// The time spent, and the records read and written, are counted for
+	/// every statement which is run with a namespace selected. The counters
+	/// are held in memory, and are not shared between nodes in a cluster.
+	pub fn usage(&self) -> Vec<(String, Usage)> {
+		self.accounting.all()
+	}
+
+	/// Get the resources used by a single namespace
+	pub(crate) fn ns_usage(&self, ns: &str) -> Usage {
+		self.accounting.get(ns)
+	}
+
+	/// Add the resources used by a statement to a namespace
+	pub(crate) fn add_usage(&self, ns: &str, time: Duration, counter: &Counter) {
+		self.accounting.add(ns, time, counter)
+	}
+
+	/// Record a full table scan which an index on the field would have avoided
+	pub(crate) fn add_suggestion(&self, ns: &str, db: &str, tb: &str, field: &Idiom) {
+		self.advisor.add(ns, db, tb, field)
+	}
+
+	/// Get the indexes which would have avoided full table scans in a database
+	pub(crate) fn suggestions(&self, ns: &str, db: &str) -> Vec<Suggestion> {
+		self.advisor.get(ns, db)
+	}
+
 	/// Parse and execute an SQL query
 	///
 	/// ```rust,no_run
@@ -593,9 +999,11 @@ impl Datastore {
 			.with_auth(sess.au.clone())
 			.with_strict(self.strict);
 		// Create a new query executor
-		let mut exe = Executor::new(self);
+		let mut exe = Executor::new(self, sess.pr, sess.dr, sess.tx, sess.wn, sess.st);
 		// Create a default context
 		let mut ctx = Context::default();
+		// Specify whether this is a dry run
+		ctx.add_dry_run(sess.dr);
 		// Set the global query timeout
 		if let Some(timeout) = self.query_timeout {
 			ctx.add_timeout(timeout);
@@ -604,6 +1012,14 @@ impl Datastore {
 		if let Some(channel) = &self.notification_channel {
 			ctx.add_notifications(Some(&channel.0));
 		}
+		// Setup the datastore write hook
+		ctx.add_hook(self.hook.as_ref());
+		// Setup the registered functions
+		ctx.add_functions(&self.functions);
+		// Setup the allowed functionality
+		ctx.add_capabilities(&self.query_capabilities);
+		// Setup the datastore reference
+		ctx.add_datastore(self);
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
@@ -646,7 +1062,7 @@ impl Datastore {
 			.with_auth(sess.au.clone())
 			.with_strict(self.strict);
 		// Start a new transaction
-		let txn = self.transaction(val.writeable(), false).await?;
+		let txn = self.transaction_with_priority(val.writeable(), false, sess.pr).await?;
 		//
 		let txn = Arc::new(Mutex::new(txn));
 		// Create a default context
@@ -659,6 +1075,14 @@ impl Datastore {
 		if let Some(channel) = &self.notification_channel {
 			ctx.add_notifications(Some(&channel.0));
 		}
+		// Setup the datastore write hook
+		ctx.add_hook(self.hook.as_ref());
+		// Setup the registered functions
+		ctx.add_functions(&self.functions);
+		// Setup the allowed functionality
+		ctx.add_capabilities(&self.query_capabilities);
+		// Setup the datastore reference
+		ctx.add_datastore(self);
 		// Start an execution context
 		let ctx = sess.context(ctx);
 		// Store the query variables
@@ -708,4 +1132,53 @@ impl Datastore {
 		// Everything ok
 		Ok(())
 	}
+
+	/// Performs a full backup of every key in the datastore
+	///
+	/// The backup does not depend on the storage engine, so it can be restored
+	/// with [`Datastore::restore`] into a datastore using any storage engine.
+	/// The keys are read from a single snapshot, so the backup is a consistent
+	/// copy of the datastore. Storage engines which limit the lifetime of a
+	/// transaction return an error if the backup takes longer than that.
+	#[instrument(skip(self, chn))]
+	pub async fn backup(&self, chn: Sender<Vec<u8>>) -> Result<(), Error> {
+		super::backup::write(self, chn).await
+	}
+
+	/// Restores every key in a backup into an empty datastore
+	///
+	/// The backup is restored in batches, and each batch is only written once
+	/// its checksum has been verified. If the backup is invalid, or fails its
+	/// checksum, any keys which were already restored are removed again.
+	#[instrument(skip(self, dump))]
+	pub async fn restore<R>(&self, mut dump: R) -> Result<(), Error>
+	where
+		R: AsyncRead + Unpin,
+	{
+		// Check that the datastore is empty
+		if !super::backup::empty(self).await? {
+			return Err(Error::RestoreNotEmpty);
+		}
+		// Process the restore
+		match super::backup::read(self, &mut dump).await {
+			Ok(()) => Ok(()),
+			Err(e) => {
+				super::backup::clear(self).await?;
+				Err(e)
+			}
+		}
+	}
+
+	/// Get the range which contains every key in the datastore
+	pub(super) fn keyspace(&self) -> Range<Key> {
+		match &self.inner {
+			// Keys from 0xff onwards are reserved for the system
+			#[cfg(feature = "kv-fdb")]
+			Inner::FoundationDB(_) => vec![]..vec![0xff],
+			// Keys are compared bytewise, so this contains every key
+			// which does not start with 64 bytes of 0xff
+			#[allow(unreachable_patterns)]
+			_ => vec![]..vec![0xff; 64],
+		}
+	}
 }
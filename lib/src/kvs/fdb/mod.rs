@@ -138,6 +138,10 @@ impl Transaction {
 		};
 		match r {
 			Ok(_r) => {}
+			// The transaction conflicted with another transaction (not_committed)
+			Err(e) if e.code() == 1020 => {
+				return Err(Error::TxRetryable);
+			}
 			Err(e) => {
 				return Err(Error::Tx(format!("Transaction commit error: {}", e)));
 			}
@@ -318,6 +322,28 @@ impl Transaction {
 		// Return result
 		Ok(())
 	}
+	/// Delete a range of keys, without reading them first
+	pub async fn delr<K>(&mut self, rng: Range<K>) -> Result<(), Error>
+	where
+		K: Into<Key>,
+	{
+		// Check to see if transaction is closed
+		if self.ok {
+			return Err(Error::TxFinished);
+		}
+		// Check to see if transaction is writable
+		if !self.rw {
+			return Err(Error::TxReadonly);
+		}
+		// Delete the key range
+		let beg: Vec<u8> = rng.start.into();
+		let end: Vec<u8> = rng.end.into();
+		let tx = self.tx.lock().await;
+		let tx = tx.as_ref().unwrap();
+		tx.clear_range(beg.as_slice(), end.as_slice());
+		// Return result
+		Ok(())
+	}
 	/// Delete a key
 	pub async fn delc<K, V>(&mut self, key: K, chk: Option<V>) -> Result<(), Error>
 	where
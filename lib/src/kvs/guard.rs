@@ -0,0 +1,130 @@
+use crate::dbs::Auth;
+use crate::err::Error;
+use crate::kvs::kv::Key;
+
+/// Restricts the keys which a transaction is allowed to access to
+/// those belonging to the authenticated namespace and database.
+///
+/// The checks here are independent of the permission checks which are
+/// performed when processing statements, so that a bug in a higher layer
+/// can not read or write keys belonging to another namespace or database.
+#[derive(Clone, Debug)]
+pub struct Guard {
+	prefixes: Vec<Key>,
+}
+
+impl Guard {
+	/// Create a guard for the specified authentication, returning [`None`]
+	/// when the authentication is not restricted to a single namespace.
+	///
+	/// Anonymous sessions are restricted to the selected namespace and
+	/// database, as they are only ever allowed to access tables there.
+	pub fn new(auth: &Auth, ns: Option<&str>, db: Option<&str>) -> Option<Guard> {
+		match auth {
+			Auth::Kv => None,
+			Auth::No => Some(match (ns, db) {
+				(Some(ns), Some(db)) => Guard::db(ns, db),
+				(Some(ns), None) => Guard::ns(ns),
+				(None, _) => Guard {
+					prefixes: vec![Guard::root()],
+				},
+			}),
+			Auth::Ns(ns) => Some(Guard::ns(ns)),
+			Auth::Db(ns, db) | Auth::Sc(ns, db, _) => Some(Guard::db(ns, db)),
+		}
+	}
+
+	// Keys directly under the root hold node metadata and the
+	// namespace definitions, and never hold any tenant data
+	fn root() -> Key {
+		vec![b'/', b'!']
+	}
+
+	fn ns(ns: &str) -> Guard {
+		Guard {
+			prefixes: vec![Guard::root(), crate::key::namespace::new(ns).into()],
+		}
+	}
+
+	fn db(ns: &str, db: &str) -> Guard {
+		// The namespace level definitions are needed to process the database
+		let mut nsd: Key = crate::key::namespace::new(ns).into();
+		nsd.push(b'!');
+		Guard {
+			prefixes: vec![Guard::root(), nsd, crate::key::database::new(ns, db).into()],
+		}
+	}
+
+	/// Check that a single key is within the guarded keyspace.
+	pub fn check(&self, key: &[u8]) -> Result<(), Error> {
+		match self.prefixes.iter().any(|p| key.starts_with(p)) {
+			true => Ok(()),
+			false => Err(Error::TxKeyOutOfScope),
+		}
+	}
+
+	/// Check that a whole key range is within the guarded keyspace.
+	pub fn check_range(&self, beg: &[u8], end: &[u8]) -> Result<(), Error> {
+		match self.prefixes.iter().any(|p| beg.starts_with(p) && end.starts_with(p)) {
+			true => Ok(()),
+			false => Err(Error::TxKeyOutOfScope),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+
+	#[test]
+	fn guard_kv() {
+		assert!(Guard::new(&Auth::Kv, Some("test"), Some("test")).is_none());
+	}
+
+	#[test]
+	fn guard_ns() {
+		let guard = Guard::new(&Auth::Ns("test".into()), None, None).unwrap();
+		let key: Key = crate::key::thing::new("test", "test", "person", &"tobie".into()).into();
+		assert!(guard.check(&key).is_ok());
+		let key: Key = crate::key::thing::new("other", "test", "person", &"tobie".into()).into();
+		assert!(matches!(guard.check(&key), Err(Error::TxKeyOutOfScope)));
+		let key: Key = crate::key::ns::new("other").into();
+		assert!(guard.check(&key).is_ok());
+	}
+
+	#[test]
+	fn guard_db() {
+		let guard = Guard::new(&Auth::Db("test".into(), "test".into()), None, None).unwrap();
+		let key: Key = crate::key::thing::new("test", "test", "person", &"tobie".into()).into();
+		assert!(guard.check(&key).is_ok());
+		let key: Key = crate::key::thing::new("test", "other", "person", &"tobie".into()).into();
+		assert!(matches!(guard.check(&key), Err(Error::TxKeyOutOfScope)));
+		let key: Key = crate::key::db::new("test", "test").into();
+		assert!(guard.check(&key).is_ok());
+		// Prefixes of the guarded database must not match other databases
+		let key: Key = crate::key::thing::new("test", "testing", "person", &"tobie".into()).into();
+		assert!(guard.check(&key).is_err());
+	}
+
+	#[test]
+	fn guard_no() {
+		let guard = Guard::new(&Auth::No, Some("test"), Some("test")).unwrap();
+		let key: Key = crate::key::thing::new("test", "test", "person", &"tobie".into()).into();
+		assert!(guard.check(&key).is_ok());
+		let key: Key = crate::key::thing::new("other", "test", "person", &"tobie".into()).into();
+		assert!(matches!(guard.check(&key), Err(Error::TxKeyOutOfScope)));
+		let guard = Guard::new(&Auth::No, None, None).unwrap();
+		let key: Key = crate::key::thing::new("test", "test", "person", &"tobie".into()).into();
+		assert!(matches!(guard.check(&key), Err(Error::TxKeyOutOfScope)));
+	}
+
+	#[test]
+	fn guard_range() {
+		let guard = Guard::new(&Auth::Db("test".into(), "test".into()), None, None).unwrap();
+		let beg = crate::key::thing::prefix("test", "test", "person");
+		let end = crate::key::thing::suffix("test", "test", "person");
+		assert!(guard.check_range(&beg, &end).is_ok());
+		let end = crate::key::thing::suffix("test", "zzz", "person");
+		assert!(guard.check_range(&beg, &end).is_err());
+	}
+}
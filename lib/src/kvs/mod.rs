@@ -12,13 +12,19 @@
 //! - `speedb`: [SpeedyDB](https://github.com/speedb-io/speedb) fork of rocksDB making it faster (Redis is using speedb but this is not acid transactions)
 //! - `tikv`: [TiKV](https://github.com/tikv/tikv) a distributed, and transactional key-value database
 //! - `mem`: in-memory database
+mod admission;
+mod backup;
 mod cache;
+mod capabilities;
+mod cursor;
 mod ds;
 mod fdb;
+mod guard;
 mod indxdb;
 mod kv;
 mod mem;
 mod rocksdb;
+mod sequences;
 mod speedb;
 mod tikv;
 mod tx;
@@ -26,6 +32,10 @@ mod tx;
 #[cfg(test)]
 mod tests;
 
+pub use self::admission::Priority;
+pub use self::capabilities::*;
+pub use self::cursor::*;
 pub use self::ds::*;
+pub use self::guard::*;
 pub use self::kv::*;
 pub use self::tx::*;
@@ -0,0 +1,23 @@
+use futures::lock::Mutex;
+use std::collections::HashMap;
+use std::ops::Range;
+use std::sync::Arc;
+
+/// The namespace, database, and name of a sequence
+pub(super) type Name = (String, String, String);
+
+/// The values which have been allocated for each sequence, but not yet used
+#[derive(Default)]
+pub(super) struct Sequences {
+	// Each sequence is locked separately, so that allocating values
+	// for one sequence does not block any other sequence
+	entries: std::sync::Mutex<HashMap<Name, Arc<Mutex<Range<i64>>>>>,
+}
+
+impl Sequences {
+	/// Get the values which have been allocated for a sequence
+	pub(super) fn get(&self, name: &Name) -> Arc<Mutex<Range<i64>>> {
+		let mut entries = self.entries.lock().unwrap_or_else(|e| e.into_inner());
+		entries.entry(name.clone()).or_default().clone()
+	}
+}
@@ -0,0 +1,45 @@
+#[tokio::test]
+#[serial]
+async fn backup_and_restore() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.set("test1", "1").await.unwrap();
+	tx.set("test2", "2").await.unwrap();
+	tx.set(vec![0xff, 0x01], "3").await.unwrap();
+	tx.commit().await.unwrap();
+	// Write enough keys to span several read batches and blocks
+	for i in 0..3 {
+		let mut tx = ds.transaction(true, false).await.unwrap();
+		for j in 0..1000 {
+			tx.set(format!("many:{i}:{j:04}"), vec![0; 100]).await.unwrap();
+		}
+		tx.commit().await.unwrap();
+	}
+	// Backup the datastore
+	let (snd, rcv) = channel::unbounded();
+	ds.backup(snd).await.unwrap();
+	let mut dump = vec![];
+	while let Ok(v) = rcv.try_recv() {
+		dump.extend(v);
+	}
+	// A backup is not restored into a datastore which has data
+	assert!(matches!(ds.restore(dump.as_slice()).await, Err(crate::err::Error::RestoreNotEmpty)));
+	// A corrupted backup is not restored
+	let ds = new_ds().await;
+	let mut bad = dump.clone();
+	let pos = bad.len() / 2;
+	bad[pos] ^= 0xff;
+	assert!(ds.restore(bad.as_slice()).await.is_err());
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	assert!(tx.getr(vec![]..vec![0xff; 64], 1).await.unwrap().is_empty());
+	tx.cancel().await.unwrap();
+	// Restore the backup
+	ds.restore(dump.as_slice()).await.unwrap();
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	assert_eq!(tx.get("test1").await.unwrap().unwrap(), b"1");
+	assert_eq!(tx.get("test2").await.unwrap().unwrap(), b"2");
+	assert_eq!(tx.get(vec![0xff, 0x01]).await.unwrap().unwrap(), b"3");
+	assert_eq!(tx.getp("many:", u32::MAX).await.unwrap().len(), 3000);
+	tx.cancel().await.unwrap();
+}
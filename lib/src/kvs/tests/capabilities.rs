@@ -0,0 +1,34 @@
+#[tokio::test]
+#[serial]
+async fn capabilities() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	let caps = ds.capabilities();
+	// Check that transactions report the datastore capabilities
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	assert_eq!(tx.capabilities(), caps);
+	// Insert some keys to delete
+	for i in 0..10 {
+		tx.set(format!("test{i}"), "ok").await.unwrap();
+	}
+	tx.set("tesu", "ok").await.unwrap();
+	tx.commit().await.unwrap();
+	// Check that range deletes work with or without native support
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.delr("test".."tesu", u32::MAX).await.unwrap();
+	tx.commit().await.unwrap();
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	assert!(tx.getr("test".."tesu", u32::MAX).await.unwrap().is_empty());
+	assert!(tx.exi("tesu").await.unwrap());
+	tx.cancel().await.unwrap();
+	// Check that snapshots do not see later commits
+	if caps.snapshots {
+		let mut tx1 = ds.transaction(false, false).await.unwrap();
+		assert!(tx1.get("snap").await.unwrap().is_none());
+		let mut tx2 = ds.transaction(true, false).await.unwrap();
+		tx2.set("snap", "ok").await.unwrap();
+		tx2.commit().await.unwrap();
+		assert!(tx1.get("snap").await.unwrap().is_none());
+		tx1.cancel().await.unwrap();
+	}
+}
@@ -14,12 +14,17 @@ mod mem {
 	}
 
 	include!("helper.rs");
+	include!("backup.rs");
+	include!("capabilities.rs");
 	include!("cluster_init.rs");
 	include!("lq.rs");
 	include!("lv.rs");
 	include!("raw.rs");
+	include!("sequence.rs");
 	include!("snapshot.rs");
+	include!("state.rs");
 	include!("tb.rs");
+	include!("ttl.rs");
 	include!("multireader.rs");
 }
 
@@ -41,12 +46,16 @@ mod rocksdb {
 	}
 
 	include!("helper.rs");
+	include!("backup.rs");
+	include!("capabilities.rs");
 	include!("cluster_init.rs");
 	include!("lq.rs");
 	include!("lv.rs");
 	include!("raw.rs");
+	include!("sequence.rs");
 	include!("snapshot.rs");
 	include!("tb.rs");
+	include!("ttl.rs");
 	include!("multireader.rs");
 	include!("multiwriter_different_keys.rs");
 	include!("multiwriter_same_keys_conflict.rs");
@@ -70,12 +79,16 @@ mod speedb {
 	}
 
 	include!("helper.rs");
+	include!("backup.rs");
+	include!("capabilities.rs");
 	include!("cluster_init.rs");
 	include!("lq.rs");
 	include!("lv.rs");
 	include!("raw.rs");
+	include!("sequence.rs");
 	include!("snapshot.rs");
 	include!("tb.rs");
+	include!("ttl.rs");
 	include!("multireader.rs");
 	include!("multiwriter_different_keys.rs");
 	include!("multiwriter_same_keys_conflict.rs");
@@ -104,11 +117,15 @@ mod tikv {
 
 	include!("cluster_init.rs");
 	include!("helper.rs");
+	include!("backup.rs");
+	include!("capabilities.rs");
 	include!("lq.rs");
 	include!("lv.rs");
 	include!("raw.rs");
+	include!("sequence.rs");
 	include!("snapshot.rs");
 	include!("tb.rs");
+	include!("ttl.rs");
 	include!("multireader.rs");
 	include!("multiwriter_different_keys.rs");
 	include!("multiwriter_same_keys_conflict.rs");
@@ -137,11 +154,15 @@ mod fdb {
 
 	include!("cluster_init.rs");
 	include!("helper.rs");
+	include!("backup.rs");
+	include!("capabilities.rs");
 	include!("lq.rs");
 	include!("lv.rs");
 	include!("raw.rs");
+	include!("sequence.rs");
 	include!("snapshot.rs");
 	include!("tb.rs");
+	include!("ttl.rs");
 	include!("multireader.rs");
 	include!("multiwriter_different_keys.rs");
 	include!("multiwriter_same_keys_allow.rs");
@@ -222,3 +222,72 @@ async fn scan() {
 	assert_eq!(val[1].1, b"2");
 	tx.cancel().await.unwrap();
 }
+
+#[tokio::test]
+#[serial]
+async fn cursor() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	// Create a writeable transaction with more keys than a single batch
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	for i in 0..2500 {
+		assert!(tx.put(format!("test{i:04}"), i.to_string()).await.is_ok());
+	}
+	tx.commit().await.unwrap();
+	// Create a readonly transaction
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	let mut cur = tx.cursor("test0000".."test9999");
+	let mut num = 0;
+	while let Some((k, v)) = cur.next().await.unwrap() {
+		assert_eq!(k, format!("test{num:04}").into_bytes());
+		assert_eq!(v, num.to_string().into_bytes());
+		num += 1;
+	}
+	assert_eq!(num, 2500);
+	tx.cancel().await.unwrap();
+	// Create a readonly transaction
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	let mut cur = tx.cursor("test3000".."test9999");
+	assert!(cur.next().await.unwrap().is_none());
+	tx.cancel().await.unwrap();
+}
+
+#[tokio::test]
+#[serial]
+async fn versionstamp() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	// The versionstamp is the same for the whole transaction
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	let a = tx.versionstamp();
+	assert_eq!(a, tx.versionstamp());
+	tx.cancel().await.unwrap();
+	// Later transactions get later versionstamps
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	let b = tx.versionstamp();
+	tx.cancel().await.unwrap();
+	assert!(a < b, "a = {a:?}, b = {b:?}");
+}
+
+#[tokio::test]
+#[serial]
+async fn guard_anonymous() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	// Create a writeable transaction
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	let key = crate::key::thing::new("other", "test", "person", &"tobie".into());
+	assert!(tx.put(key, "ok").await.is_ok());
+	tx.commit().await.unwrap();
+	// An anonymous session can not read another namespace
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	tx.guard(&crate::dbs::Auth::No, Some("test"), Some("test"));
+	let key = crate::key::thing::new("other", "test", "person", &"tobie".into());
+	let res = tx.get(key).await;
+	assert!(matches!(res, Err(crate::err::Error::TxKeyOutOfScope)));
+	let beg = crate::key::thing::prefix("other", "test", "person");
+	let end = crate::key::thing::suffix("other", "test", "person");
+	let res = tx.scan(beg..end, 1000).await;
+	assert!(matches!(res, Err(crate::err::Error::TxKeyOutOfScope)));
+	tx.cancel().await.unwrap();
+}
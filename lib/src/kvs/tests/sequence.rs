@@ -0,0 +1,29 @@
+#[tokio::test]
+#[serial]
+async fn sequence_allocation() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	let ses = crate::dbs::Session::for_kv().with_ns("test").with_db("test");
+	// Define a sequence and take some values
+	let sql = "
+		DEFINE SEQUENCE seq START 10;
+		RETURN sequence::next('seq');
+		RETURN sequence::next('seq');
+	";
+	let res = &mut ds.execute(sql, &ses, None).await.unwrap();
+	assert!(res.remove(0).result.is_ok());
+	assert_eq!(res.remove(0).result.unwrap(), crate::sql::Value::from(10));
+	assert_eq!(res.remove(0).result.unwrap(), crate::sql::Value::from(11));
+	// Redefining the sequence only restarts it when specified
+	let sql = "
+		DEFINE SEQUENCE seq START 10;
+		RETURN sequence::next('seq');
+		DEFINE SEQUENCE seq START 10 RESTART;
+		RETURN sequence::next('seq');
+	";
+	let res = &mut ds.execute(sql, &ses, None).await.unwrap();
+	assert!(res.remove(0).result.is_ok());
+	assert_eq!(res.remove(0).result.unwrap(), crate::sql::Value::from(12));
+	assert!(res.remove(0).result.is_ok());
+	assert_eq!(res.remove(0).result.unwrap(), crate::sql::Value::from(10));
+}
@@ -0,0 +1,28 @@
+#[tokio::test]
+#[serial]
+async fn save_and_load_state() {
+	// Create a new datastore
+	let ds = new_ds().await;
+	// Seed the datastore
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.set("test", "some text").await.unwrap();
+	tx.commit().await.unwrap();
+	// Save the seeded state
+	ds.save_state("seeded").await.unwrap();
+	// Modify the datastore
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.set("test", "other text").await.unwrap();
+	tx.set("temp", "temporary").await.unwrap();
+	tx.commit().await.unwrap();
+	// Reset to the seeded state
+	ds.load_state("seeded").await.unwrap();
+	// Check that the datastore was reset
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	let val = tx.get("test").await.unwrap().unwrap();
+	assert_eq!(val, b"some text");
+	let val = tx.get("temp").await.unwrap();
+	assert!(val.is_none());
+	tx.cancel().await.unwrap();
+	// Check that unknown snapshots are rejected
+	assert!(ds.load_state("unknown").await.is_err());
+}
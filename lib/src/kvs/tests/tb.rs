@@ -27,6 +27,7 @@ async fn table_definitions_can_be_scanned() {
 		view: None,
 		permissions: Default::default(),
 		changefeed: None,
+		expire: None,
 	};
 	match tx.set(&key, &value).await {
 		Ok(_) => {}
@@ -69,6 +70,7 @@ async fn table_definitions_can_be_deleted() {
 		view: None,
 		permissions: Default::default(),
 		changefeed: None,
+		expire: None,
 	};
 	match tx.set(&key, &value).await {
 		Ok(_) => {}
@@ -0,0 +1,79 @@
+#[tokio::test]
+#[serial]
+async fn expire_keys() {
+	use crate::key::tl;
+	use std::time::Duration;
+	// Create a new datastore
+	let ds = new_ds().await.with_key_expiry(true);
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.set_ttl("test1", "1", Duration::from_millis(1)).await.unwrap();
+	tx.set_ttl("test2", "2", Duration::from_secs(3600)).await.unwrap();
+	tx.set_ttl("test3", "3", Duration::from_millis(1)).await.unwrap();
+	tx.set("test3", "3").await.unwrap();
+	tx.set("test4", "4").await.unwrap();
+	tx.commit().await.unwrap();
+	// Wait for the keys to expire
+	tokio::time::sleep(Duration::from_millis(10)).await;
+	// Check that expired keys are not returned
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	assert!(tx.get("test1").await.unwrap().is_none());
+	assert!(!tx.exi("test1").await.unwrap());
+	assert_eq!(tx.get("test2").await.unwrap().unwrap(), b"2");
+	assert_eq!(tx.get("test3").await.unwrap().unwrap(), b"3");
+	assert_eq!(tx.getr("test".."tesu", u32::MAX).await.unwrap().len(), 3);
+	assert_eq!(tx.getp("test", u32::MAX).await.unwrap().len(), 3);
+	let mut cur = tx.cursor("test".."tesu");
+	let mut num = 0;
+	while cur.next().await.unwrap().is_some() {
+		num += 1;
+	}
+	assert_eq!(num, 3);
+	tx.cancel().await.unwrap();
+	// Check that an expired key can be inserted again
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.put("test1", "5").await.unwrap();
+	tx.set_ttl("test5", "5", Duration::from_millis(1)).await.unwrap();
+	tx.commit().await.unwrap();
+	tokio::time::sleep(Duration::from_millis(10)).await;
+	// Check that expired keys are removed from the storage engine
+	ds.expire().await.unwrap();
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	assert_eq!(tx.get("test1").await.unwrap().unwrap(), b"5");
+	assert!(tx.scan("test5".."test6", u32::MAX).await.unwrap().is_empty());
+	assert_eq!(tx.scan(tl::prefix()..tl::suffix(), u32::MAX).await.unwrap().len(), 1);
+	tx.cancel().await.unwrap();
+}
+
+#[tokio::test]
+#[serial]
+async fn expire_keys_disabled() {
+	use crate::err::Error;
+	use std::time::Duration;
+	// Create a new datastore
+	let ds = new_ds().await;
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	let res = tx.set_ttl("test1", "1", Duration::from_millis(1)).await;
+	assert!(matches!(res, Err(Error::KeyExpiryDisabled)));
+	tx.cancel().await.unwrap();
+}
+
+#[tokio::test]
+#[serial]
+async fn expire_keys_deleted_range() {
+	use crate::key::tl;
+	use std::time::Duration;
+	// Create a new datastore
+	let ds = new_ds().await.with_key_expiry(true);
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.set_ttl("test1", "1", Duration::from_secs(3600)).await.unwrap();
+	tx.set_ttl("test2", "2", Duration::from_secs(3600)).await.unwrap();
+	tx.commit().await.unwrap();
+	// Delete the keys along with their expiry times
+	let mut tx = ds.transaction(true, false).await.unwrap();
+	tx.delp("test", u32::MAX).await.unwrap();
+	tx.commit().await.unwrap();
+	let mut tx = ds.transaction(false, false).await.unwrap();
+	assert!(tx.scan("test".."tesu", u32::MAX).await.unwrap().is_empty());
+	assert!(tx.scan(tl::prefix()..tl::suffix(), u32::MAX).await.unwrap().is_empty());
+	tx.cancel().await.unwrap();
+}
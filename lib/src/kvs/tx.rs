@@ -1,16 +1,24 @@
+use super::admission::Permit;
+use super::cursor::Cursor;
 use super::kv::Add;
 use super::kv::Convert;
+use super::sequences::Name;
+use super::sequences::Sequences;
 use super::Key;
 use super::Val;
+use crate::cf;
+use crate::cf::mutations::TableMutation;
 use crate::dbs::cl::ClusterMembership;
 use crate::dbs::cl::Timestamp;
 use crate::err::Error;
 use crate::key::hb::Hb;
 use crate::key::lq::Lq;
 use crate::key::lv::Lv;
-use crate::key::{lq, thing};
+use crate::key::{lq, te, thing, tl};
 use crate::kvs::cache::Cache;
 use crate::kvs::cache::Entry;
+use crate::kvs::Capabilities;
+use crate::kvs::Guard;
 use crate::kvs::LqValue;
 use crate::sql;
 use crate::sql::paths::EDGE;
@@ -19,7 +27,10 @@ use crate::sql::paths::OUT;
 use crate::sql::thing::Thing;
 use crate::sql::value::Value;
 use crate::sql::Strand;
+use crate::vs::HybridLogicalClock;
+use crate::vs::Versionstamp;
 use channel::Sender;
+use chrono::Utc;
 use sql::permission::Permissions;
 use sql::statements::DefineAnalyzerStatement;
 use sql::statements::DefineDatabaseStatement;
@@ -31,14 +42,18 @@ use sql::statements::DefineLoginStatement;
 use sql::statements::DefineNamespaceStatement;
 use sql::statements::DefineParamStatement;
 use sql::statements::DefineScopeStatement;
+use sql::statements::DefineSequenceStatement;
 use sql::statements::DefineTableStatement;
 use sql::statements::DefineTokenStatement;
 use sql::statements::LiveStatement;
+use std::collections::BTreeSet;
 use std::fmt;
 use std::fmt::Debug;
 use std::ops::Range;
 use std::sync::Arc;
+use std::time::Duration;
 use std::time::{SystemTime, UNIX_EPOCH};
+use trice::Instant;
 use uuid::Uuid;
 
 /// A set of undoable updates and requests against a dataset.
@@ -46,6 +61,18 @@ use uuid::Uuid;
 pub struct Transaction {
 	pub(super) inner: Inner,
 	pub(super) cache: Cache,
+	pub(super) guard: Option<Guard>,
+	pub(super) capabilities: Capabilities,
+	pub(super) permit: Option<Permit>,
+	pub(super) hlc: Arc<HybridLogicalClock>,
+	pub(super) vs: Option<Versionstamp>,
+	pub(super) cf: cf::Writer,
+	// Whether keys can have a time-to-live
+	pub(super) ttl: bool,
+	// The values which have been allocated for each sequence
+	pub(super) sequences: Arc<Sequences>,
+	// The sequences whose allocated values are discarded on commit
+	pub(super) restarts: BTreeSet<Name>,
 }
 
 #[allow(clippy::large_enum_variant)]
@@ -184,7 +211,21 @@ impl Transaction {
 	pub async fn commit(&mut self) -> Result<(), Error> {
 		#[cfg(debug_assertions)]
 		trace!("Commit");
-		match self {
+		// Write any buffered change feed entries
+		self.write_changes().await?;
+		// Hold the restarted sequences, so that none of their
+		// allocated values are used while the transaction commits
+		let restarts = std::mem::take(&mut self.restarts)
+			.iter()
+			.map(|v| self.sequences.get(v))
+			.collect::<Vec<_>>();
+		let mut held = Vec::with_capacity(restarts.len());
+		for v in restarts.iter() {
+			held.push(v.lock().await);
+		}
+		// Measure how long the commit takes
+		let now = Instant::now();
+		let res = match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
 				inner: Inner::Mem(v),
@@ -217,7 +258,69 @@ impl Transaction {
 			} => v.commit().await,
 			#[allow(unreachable_patterns)]
 			_ => unreachable!(),
+		};
+		// Record the commit latency
+		if let Some(permit) = &self.permit {
+			permit.observe(now.elapsed());
+		}
+		// Discard the values allocated for restarted sequences
+		if res.is_ok() {
+			for mut v in held {
+				*v = 0..0;
+			}
+		}
+		res
+	}
+
+	/// Get the versionstamp of this transaction.
+	///
+	/// The versionstamp is taken from the hybrid logical clock of the datastore
+	/// the first time it is requested, which is normally while the transaction
+	/// is being committed, and stays the same for the rest of the transaction.
+	/// Versionstamps are ordered across all transactions on the datastore, even
+	/// if the system clock stalls or goes backwards.
+	pub fn versionstamp(&mut self) -> Versionstamp {
+		*self.vs.get_or_insert_with(|| self.hlc.now())
+	}
+
+	/// Merge a versionstamp which was read from the datastore, so that the
+	/// versionstamps of transactions which start afterwards are greater.
+	pub(crate) fn observe(&self, vs: Versionstamp) {
+		self.hlc.observe(vs)
+	}
+
+	/// Buffer a change made to a table.
+	///
+	/// The change is written to the change feed of the database, under the
+	/// versionstamp of this transaction, when the transaction is committed.
+	/// Expired change feed entries are removed from the database at the same time.
+	pub(crate) fn record_change(&mut self, ns: &str, db: &str, tb: &str, change: TableMutation) {
+		self.cf.record(ns, db, tb, change)
+	}
+
+	/// Write the buffered changes to the change feed of each database.
+	async fn write_changes(&mut self) -> Result<(), Error> {
+		// Check if there are any changes
+		if self.cf.is_empty() {
+			return Ok(());
+		}
+		// Get the versionstamp of this transaction
+		let vs = self.versionstamp();
+		// Take the buffered changes
+		let buf = std::mem::take(&mut self.cf);
+		// The databases which have changes
+		let mut dbs = BTreeSet::new();
+		// Write the changes of each table
+		for ((ns, db, tb), mutations) in buf.changes {
+			let key = crate::key::cf::Cf::new(&ns, &db, vs, &tb);
+			self.set(key, mutations).await?;
+			dbs.insert((ns, db));
+		}
+		// Remove any expired changes, once for each database
+		for (ns, db) in dbs {
+			cf::gc(self, &ns, &db, vs).await?;
 		}
+		Ok(())
 	}
 
 	/// Delete a key from the datastore.
@@ -228,6 +331,18 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Del {:?}", key);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Remove any expiry from the key
+		self.persist(&key).await?;
+		self.del_raw(key).await
+	}
+
+	/// Delete a key from the storage engine, ignoring any guard or expiry.
+	#[allow(unused_variables)]
+	async fn del_raw(&mut self, key: Key) -> Result<(), Error> {
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -264,6 +379,44 @@ impl Transaction {
 		}
 	}
 
+	/// Delete a range of keys natively in the storage engine, without reading them first.
+	///
+	/// This must only be called when the storage engine supports range deletes.
+	#[allow(unused_variables)]
+	async fn delr_native(&mut self, rng: Range<Key>) -> Result<(), Error> {
+		#[cfg(debug_assertions)]
+		trace!("Delr {:?}..{:?}", rng.start, rng.end);
+		if let Some(guard) = &self.guard {
+			guard.check_range(&rng.start, &rng.end)?;
+		}
+		// Remove any expiry from the keys. The expiry times of a range of keys
+		// are stored in the same order, and any keys which are left queued for
+		// expiry are skipped by Datastore::expire.
+		if self.ttl {
+			self.delr_raw(tl::new(&rng.start)..tl::new(&rng.end)).await?;
+		}
+		self.delr_raw(rng).await
+	}
+
+	/// Delete a range of keys natively in the storage engine, ignoring any guard or expiry.
+	#[allow(unused_variables)]
+	async fn delr_raw(&mut self, rng: Range<Key>) -> Result<(), Error> {
+		match self {
+			#[cfg(feature = "kv-fdb")]
+			Transaction {
+				inner: Inner::FoundationDB(v),
+				..
+			} => v.delr(rng).await,
+			#[allow(unreachable_patterns)]
+			_ => unreachable!(),
+		}
+	}
+
+	/// Get the features which are natively supported by the storage engine
+	pub fn capabilities(&self) -> Capabilities {
+		self.capabilities
+	}
+
 	/// Check if a key exists in the datastore.
 	#[allow(unused_variables)]
 	pub async fn exi<K>(&mut self, key: K) -> Result<bool, Error>
@@ -272,6 +425,14 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Exi {:?}", key);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Ignore the key if it has expired
+		if self.expired(&key).await? {
+			return Ok(false);
+		}
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -316,6 +477,20 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Get {:?}", key);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Ignore the key if it has expired
+		if self.expired(&key).await? {
+			return Ok(None);
+		}
+		self.get_raw(key).await
+	}
+
+	/// Fetch a key from the storage engine, ignoring any guard or expiry.
+	#[allow(unused_variables)]
+	async fn get_raw(&mut self, key: Key) -> Result<Option<Val>, Error> {
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -361,6 +536,18 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Set {:?} => {:?}", key, val);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Remove any expiry from the key
+		self.persist(&key).await?;
+		self.set_raw(key, val.into()).await
+	}
+
+	/// Insert or update a key in the storage engine, ignoring any guard or expiry.
+	#[allow(unused_variables)]
+	async fn set_raw(&mut self, key: Key, val: Val) -> Result<(), Error> {
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -406,6 +593,12 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Put {:?} => {:?}", key, val);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Remove any expiry from the key
+		self.persist(&key).await?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -445,6 +638,7 @@ impl Transaction {
 	/// Retrieve a specific range of keys from the datastore.
 	///
 	/// This function fetches the full range of key-value pairs, in a single request to the underlying datastore.
+	/// Any keys whose time-to-live has passed, but which have not yet been removed, are included.
 	#[allow(unused_variables)]
 	pub async fn scan<K>(&mut self, rng: Range<K>, limit: u32) -> Result<Vec<(Key, Val)>, Error>
 	where
@@ -452,6 +646,16 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Scan {:?} - {:?}", rng.start, rng.end);
+		let rng: Range<Key> = rng.start.into()..rng.end.into();
+		if let Some(guard) = &self.guard {
+			guard.check_range(&rng.start, &rng.end)?;
+		}
+		self.scan_raw(rng, limit).await
+	}
+
+	/// Retrieve a range of keys from the storage engine, ignoring any guard.
+	#[allow(unused_variables)]
+	async fn scan_raw(&mut self, rng: Range<Key>, limit: u32) -> Result<Vec<(Key, Val)>, Error> {
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -497,6 +701,12 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Putc {:?} if {:?} => {:?}", key, chk, val);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Remove any expiry from the key
+		self.persist(&key).await?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -542,6 +752,12 @@ impl Transaction {
 	{
 		#[cfg(debug_assertions)]
 		trace!("Delc {:?} if {:?}", key, chk);
+		let key: Key = key.into();
+		if let Some(guard) = &self.guard {
+			guard.check(&key)?;
+		}
+		// Remove any expiry from the key
+		self.persist(&key).await?;
 		match self {
 			#[cfg(feature = "kv-mem")]
 			Transaction {
@@ -578,6 +794,16 @@ impl Transaction {
 		}
 	}
 
+	/// Restrict the keys accessible by this transaction.
+	///
+	/// Once a guard has been set, any request for a key outside of
+	/// the namespace or database of the specified authentication
+	/// will return an [`Error::TxKeyOutOfScope`] error. Anonymous
+	/// sessions are restricted to the selected namespace and database.
+	pub fn guard(&mut self, auth: &crate::dbs::Auth, ns: Option<&str>, db: Option<&str>) {
+		self.guard = Guard::new(auth, ns, db);
+	}
+
 	// --------------------------------------------------
 	// Superjacent methods
 	// --------------------------------------------------
@@ -624,6 +850,10 @@ impl Transaction {
 				if n == i + 1 {
 					nxt = Some(k.clone());
 				}
+				// Ignore the key if it has expired
+				if self.expired(&k).await? {
+					continue;
+				}
 				// Delete
 				out.push((k, v));
 				// Count
@@ -632,6 +862,16 @@ impl Transaction {
 		}
 		Ok(out)
 	}
+	/// Iterate over a specific range of keys from the datastore.
+	///
+	/// The returned cursor fetches key-value pairs from the underlying datastore in batches of 1000,
+	/// so only a single batch is held in memory at any time.
+	pub fn cursor<K>(&mut self, rng: Range<K>) -> Cursor<'_>
+	where
+		K: Into<Key>,
+	{
+		Cursor::new(self, rng.start.into()..rng.end.into())
+	}
 	/// Delete a range of keys from the datastore.
 	///
 	/// This function fetches key-value pairs from the underlying datastore in batches of 1000.
@@ -641,6 +881,10 @@ impl Transaction {
 	{
 		let beg: Key = rng.start.into();
 		let end: Key = rng.end.into();
+		// Delete the whole range natively if the storage engine supports it
+		if limit == u32::MAX && self.capabilities.range_delete {
+			return self.delr_native(beg..end).await;
+		}
 		let mut nxt: Option<Key> = None;
 		let mut num = limit;
 		// Start processing
@@ -723,6 +967,10 @@ impl Transaction {
 				if n == i + 1 {
 					nxt = Some(k.clone());
 				}
+				// Ignore the key if it has expired
+				if self.expired(&k).await? {
+					continue;
+				}
 				// Delete
 				out.push((k, v));
 				// Count
@@ -740,6 +988,10 @@ impl Transaction {
 	{
 		let beg: Key = key.into();
 		let end: Key = beg.clone().add(0xff);
+		// Delete the whole prefix natively if the storage engine supports it
+		if limit == u32::MAX && self.capabilities.range_delete {
+			return self.delr_native(beg..end).await;
+		}
 		let mut nxt: Option<Key> = None;
 		let mut num = limit;
 		// Start processing
@@ -781,6 +1033,87 @@ impl Transaction {
 		Ok(())
 	}
 
+	// --------------------------------------------------
+	// Expiry methods
+	// --------------------------------------------------
+
+	/// Insert or update a key in the datastore, which expires after a time-to-live.
+	///
+	/// Once the key has expired it is no longer returned when fetching keys,
+	/// and it is removed from the storage engine by [`Datastore::expire`].
+	/// Writing or deleting the key again removes its time-to-live.
+	///
+	/// [`Datastore::expire`]: super::Datastore::expire
+	pub async fn set_ttl<K, V>(&mut self, key: K, val: V, ttl: Duration) -> Result<(), Error>
+	where
+		K: Into<Key> + Debug,
+		V: Into<Val> + Debug,
+	{
+		#[cfg(debug_assertions)]
+		trace!("Set {:?} => {:?} for {:?}", key, val, ttl);
+		if !self.ttl {
+			return Err(Error::KeyExpiryDisabled);
+		}
+		let key: Key = key.into();
+		// Insert the key, removing any previous expiry
+		self.set(key.clone(), val).await?;
+		// Store the time at which the key expires
+		let ts = now().saturating_add(ttl.as_millis() as u64);
+		self.set_raw(tl::new(&key), tl::encode(ts)).await?;
+		self.set_raw(te::new(ts, &key), vec![]).await?;
+		Ok(())
+	}
+
+	/// Delete a batch of keys whose time-to-live has passed, returning how many were found.
+	pub(super) async fn expire(&mut self, limit: u32) -> Result<usize, Error> {
+		let res = self.scan_raw(te::prefix()..te::suffix(now()), limit).await?;
+		for (k, _) in res.iter() {
+			if let Some((ts, key)) = te::decode(k) {
+				// Only delete the key if it was not written again since
+				if self.get_raw(tl::new(&key)).await?.map(|v| tl::decode(&v)) == Some(ts) {
+					self.del_raw(tl::new(&key)).await?;
+					self.del_raw(key).await?;
+				}
+			}
+			self.del_raw(k.clone()).await?;
+		}
+		Ok(res.len())
+	}
+
+	/// Check if a key has a time-to-live which has passed.
+	pub(super) async fn expired(&mut self, key: &[u8]) -> Result<bool, Error> {
+		if !self.expiring(key) {
+			return Ok(false);
+		}
+		match self.get_raw(tl::new(key)).await? {
+			Some(v) => Ok(tl::decode(&v) <= now()),
+			None => Ok(false),
+		}
+	}
+
+	/// Remove any time-to-live from a key, deleting the key if it has already expired.
+	async fn persist(&mut self, key: &[u8]) -> Result<(), Error> {
+		if !self.expiring(key) {
+			return Ok(());
+		}
+		if let Some(v) = self.get_raw(tl::new(key)).await? {
+			let ts = tl::decode(&v);
+			self.del_raw(tl::new(key)).await?;
+			self.del_raw(te::new(ts, key)).await?;
+			// The expired value must not be seen by a conditional write
+			if ts <= now() {
+				self.del_raw(key.to_vec()).await?;
+			}
+		}
+		Ok(())
+	}
+
+	/// Check if a key could have a time-to-live.
+	fn expiring(&self, key: &[u8]) -> bool {
+		// The keys which store the expiry times never expire
+		self.ttl && !key.starts_with(tl::PREFIX) && !key.starts_with(te::PREFIX)
+	}
+
 	// --------------------------------------------------
 	// Superimposed methods
 	// --------------------------------------------------
@@ -1233,6 +1566,29 @@ impl Transaction {
 		})
 	}
 
+	/// Retrieve all sequence definitions for a specific database.
+	pub async fn all_sq(
+		&mut self,
+		ns: &str,
+		db: &str,
+	) -> Result<Arc<[DefineSequenceStatement]>, Error> {
+		let key = crate::key::sq::prefix(ns, db);
+		Ok(if let Some(e) = self.cache.get(&key) {
+			if let Entry::Sqs(v) = e {
+				v
+			} else {
+				unreachable!();
+			}
+		} else {
+			let beg = crate::key::sq::prefix(ns, db);
+			let end = crate::key::sq::suffix(ns, db);
+			let val = self.getr(beg..end, u32::MAX).await?;
+			let val = val.convert().into();
+			self.cache.set(key, Entry::Sqs(Arc::clone(&val)));
+			val
+		})
+	}
+
 	/// Retrieve all table definitions for a specific database.
 	pub async fn all_tb(
 		&mut self,
@@ -1574,6 +1930,70 @@ impl Transaction {
 		Ok(val.into())
 	}
 
+	/// Retrieve a specific sequence definition.
+	pub async fn get_sq(
+		&mut self,
+		ns: &str,
+		db: &str,
+		sq: &str,
+	) -> Result<DefineSequenceStatement, Error> {
+		let key = crate::key::sq::new(ns, db, sq);
+		let val = self.get(key).await?.ok_or(Error::SqNotFound {
+			value: sq.to_owned(),
+		})?;
+		Ok(val.into())
+	}
+
+	/// Retrieve the last value returned by a specific sequence.
+	pub async fn get_sn(&mut self, ns: &str, db: &str, sq: &str) -> Result<Option<i64>, Error> {
+		let key = crate::key::sn::new(ns, db, sq);
+		Ok(match self.get(key).await? {
+			Some(v) => match <[u8; 8]>::try_from(v.as_slice()) {
+				Ok(v) => Some(i64::from_be_bytes(v)),
+				Err(_) => return Err(Error::Internal(format!("Invalid value for sequence {sq}"))),
+			},
+			None => None,
+		})
+	}
+
+	/// Discard the values which have been allocated for a sequence, but not
+	/// yet used, once this transaction has been committed.
+	pub(crate) fn restart_sn(&mut self, ns: &str, db: &str, sq: &str) {
+		self.restarts.insert((ns.to_owned(), db.to_owned(), sq.to_owned()));
+	}
+
+	/// Advance a specific sequence, returning the next value.
+	pub async fn next_sn(&mut self, ns: &str, db: &str, sq: &str) -> Result<i64, Error> {
+		Ok(self.alloc_sn(ns, db, sq, 1).await?.start)
+	}
+
+	/// Advance a specific sequence by a number of values, returning the
+	/// range of values which have been allocated.
+	pub async fn alloc_sn(
+		&mut self,
+		ns: &str,
+		db: &str,
+		sq: &str,
+		count: i64,
+	) -> Result<Range<i64>, Error> {
+		let def = self.get_sq(ns, db, sq).await?;
+		let beg = match self.get_sn(ns, db, sq).await? {
+			Some(v) => v.checked_add(1),
+			None => Some(def.start),
+		};
+		let val = match beg.and_then(|v| Some(v..v.checked_add(count)?)) {
+			Some(v) => v,
+			None => {
+				return Err(Error::Internal(format!(
+					"The sequence {sq} has reached its maximum value"
+				)))
+			}
+		};
+		let key = crate::key::sn::new(ns, db, sq);
+		self.set(key, (val.end - 1).to_be_bytes().to_vec()).await?;
+		Ok(val)
+	}
+
 	/// Retrieve a specific table definition.
 	pub async fn get_tb(
 		&mut self,
@@ -1979,6 +2399,29 @@ impl Transaction {
 				chn.send(bytes!("")).await?;
 			}
 		}
+		// Output SEQUENCES
+		{
+			let sqs = self.all_sq(ns, db).await?;
+			if !sqs.is_empty() {
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("-- SEQUENCES")).await?;
+				chn.send(bytes!("-- ------------------------------")).await?;
+				chn.send(bytes!("")).await?;
+				for sq in sqs.iter() {
+					// Continue from the last value returned by the sequence
+					let sq = match self.get_sn(ns, db, &sq.name).await? {
+						Some(v) => DefineSequenceStatement {
+							name: sq.name.clone(),
+							start: v.saturating_add(1),
+							restart: true,
+						},
+						None => sq.clone(),
+					};
+					chn.send(bytes!(format!("{sq};"))).await?;
+				}
+				chn.send(bytes!("")).await?;
+			}
+		}
 		// Output SCOPES
 		{
 			let scs = self.all_sc(ns, db).await?;
@@ -2103,7 +2546,7 @@ impl Transaction {
 									nxt = Some(k.clone());
 								}
 								// Parse the key and the value
-								let k: crate::key::thing::Thing = (&k).into();
+								let k = crate::key::thing::Thing::decode(&k)?;
 								let v: crate::sql::value::Value = (&v).into();
 								let t = Thing::from((k.tb, k.id));
 								// Check if this is a graph edge
@@ -2139,3 +2582,8 @@ impl Transaction {
 		Ok(())
 	}
 }
+
+/// The current time in milliseconds since the epoch
+fn now() -> u64 {
+	Utc::now().timestamp_millis() as u64
+}
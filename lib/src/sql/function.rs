@@ -89,6 +89,14 @@ impl Function {
 	pub fn is_custom(&self) -> bool {
 		matches!(self, Self::Custom(_, _))
 	}
+	/// Check if this function can modify the datastore
+	pub fn is_writeable(&self) -> bool {
+		match self {
+			Self::Custom(_, _) => true,
+			Self::Normal(f, _) if f == "sequence::next" => true,
+			_ => false,
+		}
+	}
 	/// Check if this function is a rolling function
 	pub fn is_rolling(&self) -> bool {
 		match self {
@@ -149,9 +157,23 @@ impl Function {
 				// Compute the function arguments
 				let a = try_join_all(x.iter().map(|v| v.compute(ctx, opt, txn, doc))).await?;
 				// Run the normal function
-				fnc::run(ctx, txn, doc, s, a).await
+				fnc::run(ctx, opt, txn, doc, s, a).await
 			}
 			Self::Custom(s, x) => {
+				// Check for a function registered with the datastore
+				if let Some(f) = ctx.get_function(s) {
+					// Check that registered functions are allowed
+					if !ctx.capabilities().allows_custom_functions() {
+						return Err(Error::InvalidFunction {
+							name: format!("fn::{s}"),
+							message: String::from("Registered functions are not enabled."),
+						});
+					}
+					// Compute the function arguments
+					let a = try_join_all(x.iter().map(|v| v.compute(ctx, opt, txn, doc))).await?;
+					// Run the registered function
+					return f.run(s, a);
+				}
 				// Get the function definition
 				let val = {
 					// Claim transaction
@@ -184,6 +206,12 @@ impl Function {
 			Self::Script(s, x) => {
 				#[cfg(feature = "scripting")]
 				{
+					// Check that embedded functions are allowed
+					if !ctx.capabilities().allows_scripting() {
+						return Err(Error::InvalidScript {
+							message: String::from("Embedded functions are not enabled."),
+						});
+					}
 					// Compute the function arguments
 					let a = try_join_all(x.iter().map(|v| v.compute(ctx, opt, txn, doc))).await?;
 					// Run the script function
@@ -251,6 +279,7 @@ pub(crate) fn function_names(i: &str) -> IResult<&str, &str> {
 		alt((
 			preceded(tag("array::"), function_array),
 			preceded(tag("bytes::"), function_bytes),
+			preceded(tag("count::"), function_count),
 			preceded(tag("crypto::"), function_crypto),
 			preceded(tag("duration::"), function_duration),
 			preceded(tag("encoding::"), function_encoding),
@@ -260,15 +289,17 @@ pub(crate) fn function_names(i: &str) -> IResult<&str, &str> {
 			preceded(tag("math::"), function_math),
 			preceded(tag("meta::"), function_meta),
 			preceded(tag("parse::"), function_parse),
+			preceded(tag("patch::"), function_patch),
 			preceded(tag("rand::"), function_rand),
 			preceded(tag("search::"), function_search),
+			preceded(tag("sequence::"), function_sequence),
 			preceded(tag("session::"), function_session),
 			preceded(tag("string::"), function_string),
 			preceded(tag("time::"), function_time),
 			preceded(tag("type::"), function_type),
 			preceded(tag("vector::"), function_vector),
 		)),
-		alt((tag("count"), tag("not"), tag("rand"), tag("sleep"))),
+		alt((tag("count"), tag("not"), tag("patch"), tag("rand"), tag("sleep"))),
 	)))(i)
 }
 
@@ -326,6 +357,10 @@ fn function_bytes(i: &str) -> IResult<&str, &str> {
 	alt((tag("len"),))(i)
 }
 
+fn function_count(i: &str) -> IResult<&str, &str> {
+	alt((tag("estimate"),))(i)
+}
+
 fn function_crypto(i: &str) -> IResult<&str, &str> {
 	alt((
 		preceded(tag("argon2::"), alt((tag("compare"), tag("generate")))),
@@ -376,7 +411,7 @@ fn function_geo(i: &str) -> IResult<&str, &str> {
 		tag("bearing"),
 		tag("centroid"),
 		tag("distance"),
-		preceded(tag("hash::"), alt((tag("decode"), tag("encode")))),
+		preceded(tag("hash::"), alt((tag("decode"), tag("encode"), tag("neighbours")))),
 	))(i)
 }
 
@@ -457,6 +492,10 @@ fn function_parse(i: &str) -> IResult<&str, &str> {
 	))(i)
 }
 
+fn function_patch(i: &str) -> IResult<&str, &str> {
+	alt((tag("merge"),))(i)
+}
+
 fn function_rand(i: &str) -> IResult<&str, &str> {
 	alt((
 		tag("bool"),
@@ -477,6 +516,10 @@ fn function_search(i: &str) -> IResult<&str, &str> {
 	alt((tag("score"), tag("highlight"), tag("offsets")))(i)
 }
 
+fn function_sequence(i: &str) -> IResult<&str, &str> {
+	alt((tag("next"),))(i)
+}
+
 fn function_session(i: &str) -> IResult<&str, &str> {
 	alt((
 		tag("db"),
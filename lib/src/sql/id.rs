@@ -3,6 +3,7 @@ use crate::ctx::Context;
 use crate::dbs::{Options, Transaction};
 use crate::doc::CursorDoc;
 use crate::err::Error;
+use crate::fnc::util::ulid;
 use crate::sql::array::{array, Array};
 use crate::sql::error::IResult;
 use crate::sql::escape::escape_rid;
@@ -19,7 +20,6 @@ use nom::branch::alt;
 use nom::combinator::map;
 use serde::{Deserialize, Serialize};
 use std::fmt::{self, Display, Formatter};
-use ulid::Ulid;
 
 #[derive(Clone, Debug, Eq, PartialEq, Ord, PartialOrd, Serialize, Deserialize, Hash)]
 pub enum Id {
@@ -129,9 +129,9 @@ impl Id {
 	pub fn rand() -> Self {
 		Self::String(nanoid!(20, &ID_CHARS))
 	}
-	/// Generate a new random ULID
+	/// Generate a new monotonic ULID
 	pub fn ulid() -> Self {
-		Self::String(Ulid::new().to_string())
+		Self::String(ulid::monotonic().to_string())
 	}
 	/// Generate a new random UUID
 	#[cfg(uuid_unstable)]
@@ -32,6 +32,52 @@ pub fn json(input: &str) -> Result<Value, Error> {
 	parse_impl(input.trim(), super::value::json)
 }
 
+/// Formats a SurrealQL [`Query`] in the canonical style
+///
+/// Comments are not kept when a query is parsed, so an error is
+/// returned instead of formatting a query which contains comments.
+#[instrument(name = "parser", skip_all, fields(length = input.len()))]
+pub fn format(input: &str) -> Result<String, Error> {
+	// Check that no comments would be removed
+	if comments(input) {
+		return Err(Error::QueryComments);
+	}
+	// Parse the query and output it again
+	let query = parse(input)?;
+	Ok(format!("{query:#}\n"))
+}
+
+/// Checks if there are any comments outside of strings and identifiers
+fn comments(input: &str) -> bool {
+	let mut chars = input.chars().peekable();
+	while let Some(c) = chars.next() {
+		match c {
+			// Skip over any quoted text
+			'\'' | '"' | '`' | '⟨' => {
+				let end = match c {
+					'⟨' => '⟩',
+					c => c,
+				};
+				while let Some(c) = chars.next() {
+					match c {
+						'\\' => {
+							chars.next();
+						}
+						c if c == end => break,
+						_ => (),
+					}
+				}
+			}
+			// Check for the start of a comment
+			'#' => return true,
+			'-' if chars.peek() == Some(&'-') => return true,
+			'/' if matches!(chars.peek(), Some('/' | '*')) => return true,
+			_ => (),
+		}
+	}
+	false
+}
+
 fn parse_impl<O>(input: &str, parser: impl Fn(&str) -> IResult<&str, O>) -> Result<O, Error> {
 	// Check the length of the input
 	match input.trim().len() {
@@ -114,6 +160,29 @@ mod tests {
 	use serde::Serialize;
 	use std::{collections::HashMap, time::Instant};
 
+	#[test]
+	fn format_query() {
+		let sql = "select * from test where name = 'a -- b'";
+		let res = format(sql).unwrap();
+		assert!(res.starts_with("SELECT * FROM test"));
+		assert!(res.contains("'a -- b'"));
+		assert_eq!(format(&res).unwrap(), res);
+	}
+
+	#[test]
+	fn format_query_with_comments() {
+		for sql in [
+			"SELECT * FROM test; -- comment",
+			"SELECT * FROM test; // comment",
+			"SELECT * FROM test; # comment",
+			"SELECT * FROM /* comment */ test",
+		] {
+			assert!(matches!(format(sql), Err(Error::QueryComments)), "{sql}");
+		}
+		// Comment characters in strings and identifiers are kept
+		assert!(format("SELECT * FROM `a#b`, person:⟨a//b⟩ WHERE x = \"/*\"").is_ok());
+	}
+
 	#[test]
 	fn no_ending() {
 		let sql = "SELECT * FROM test";
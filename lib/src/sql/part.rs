@@ -2,6 +2,7 @@ use crate::sql::comment::shouldbespace;
 use crate::sql::common::{closebracket, openbracket};
 use crate::sql::ending::ident as ending;
 use crate::sql::error::IResult;
+use crate::sql::expression;
 use crate::sql::fmt::Fmt;
 use crate::sql::graph::{self, Graph};
 use crate::sql::ident::{self, Ident};
@@ -139,7 +140,7 @@ impl<'a> Next<'a> for &'a [Part] {
 // ------------------------------
 
 pub fn part(i: &str) -> IResult<&str, Part> {
-	alt((all, last, index, field, value, graph, filter))(i)
+	alt((all, last, index, field, value, graph, filter, predicate))(i)
 }
 
 pub fn first(i: &str) -> IResult<&str, Part> {
@@ -196,6 +197,13 @@ pub fn filter(i: &str) -> IResult<&str, Part> {
 	Ok((i, Part::Where(v)))
 }
 
+pub fn predicate(i: &str) -> IResult<&str, Part> {
+	let (i, _) = openbracket(i)?;
+	let (i, v) = expression::binary(i)?;
+	let (i, _) = closebracket(i)?;
+	Ok((i, Part::Where(Value::from(v))))
+}
+
 pub fn value(i: &str) -> IResult<&str, Part> {
 	let (i, _) = openbracket(i)?;
 	let (i, v) = alt((
@@ -273,4 +281,24 @@ mod tests {
 		assert_eq!("[WHERE test = true]", format!("{}", out));
 		assert_eq!(out, Part::Where(Value::from(Expression::parse("test = true"))));
 	}
+
+	#[test]
+	fn part_expression_predicate() {
+		let sql = "[price > 10]";
+		let res = part(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("[WHERE price > 10]", format!("{}", out));
+		assert_eq!(out, Part::Where(Value::from(Expression::parse("price > 10"))));
+	}
+
+	#[test]
+	fn part_value_is_not_predicate() {
+		let sql = "[price]";
+		let res = part(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("[price]", format!("{}", out));
+		assert!(matches!(out, Part::Value(Value::Idiom(_))));
+	}
 }
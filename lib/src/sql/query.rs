@@ -4,6 +4,7 @@ use crate::sql::statement::{statements, Statement, Statements};
 use derive::Store;
 use nom::combinator::all_consuming;
 use serde::{Deserialize, Serialize};
+use sha2::{Digest, Sha256};
 use std::fmt::Write;
 use std::fmt::{self, Display, Formatter};
 use std::ops::Deref;
@@ -33,6 +34,124 @@ impl Display for Query {
 	}
 }
 
+impl Query {
+	/// Returns the query in its canonical form, with every literal string,
+	/// number, record id, and array of literals replaced by `?`, so that
+	/// queries which only differ in their literal values or their whitespace
+	/// are normalized to the same text.
+	pub fn normalize(&self) -> String {
+		normalize(&self.to_string())
+	}
+
+	/// Returns a stable fingerprint of the normalized query, as a hex-encoded
+	/// SHA-256 hash, which can be used to group or cache similar queries.
+	pub fn fingerprint(&self) -> String {
+		let mut hasher = Sha256::new();
+		hasher.update(self.normalize());
+		let val = hasher.finalize();
+		format!("{val:x}")
+	}
+}
+
+fn normalize(sql: &str) -> String {
+	let is_ident = |c: char| c.is_alphanumeric() || c == '_';
+	let mut out = String::with_capacity(sql.len());
+	let mut chars = sql.chars().peekable();
+	// The position of each open bracket, and whether it is an array
+	let mut arrays = Vec::new();
+	while let Some(c) = chars.next() {
+		let last = out.chars().last();
+		match c {
+			// Record ids with an escaped id
+			':' if last.map_or(false, is_ident) && matches!(chars.peek(), Some('`' | '⟨')) => {
+				let close = match chars.next() {
+					Some('`') => '`',
+					_ => '⟩',
+				};
+				while let Some(c) = chars.next() {
+					match c {
+						'\\' => {
+							chars.next();
+						}
+						c if c == close => break,
+						_ => (),
+					}
+				}
+				out.push_str(":?");
+			}
+			// Escaped identifiers are kept as they are
+			'`' | '⟨' => {
+				let close = match c {
+					'`' => '`',
+					_ => '⟩',
+				};
+				out.push(c);
+				while let Some(c) = chars.next() {
+					out.push(c);
+					match c {
+						'\\' => out.extend(chars.next()),
+						c if c == close => break,
+						_ => (),
+					}
+				}
+			}
+			// Strings, datetimes, and uuids
+			'\'' | '"' => {
+				while let Some(v) = chars.next() {
+					match v {
+						'\\' => {
+							chars.next();
+						}
+						v if v == c => break,
+						_ => (),
+					}
+				}
+				out.push('?');
+			}
+			// Numbers and durations, which may be negative
+			c if (c.is_ascii_digit()
+				|| (c == '-' && chars.peek().map_or(false, char::is_ascii_digit)))
+				&& !last.map_or(false, is_ident) =>
+			{
+				while chars.next_if(|&c| is_ident(c) || c == '.').is_some() {}
+				out.push('?');
+			}
+			// Array literals, but not the parts of an idiom such as `tags[0]`
+			'[' => {
+				arrays.push((
+					out.len(),
+					!last.map_or(false, |c| is_ident(c) || c == ']' || c == ')'),
+				));
+				out.push(c);
+			}
+			// Arrays which only contain literal values are collapsed to a
+			// single placeholder, so that lists of any length are the same
+			']' => match arrays.pop() {
+				Some((pos, true))
+					if out[pos + 1..].chars().all(|c| matches!(c, '?' | ',' | ' ')) =>
+				{
+					out.truncate(pos);
+					out.push('?');
+				}
+				_ => out.push(c),
+			},
+			// Record ids, but not paths such as `type::thing`
+			':' if last.map_or(false, is_ident) && chars.peek().map_or(false, |&c| is_ident(c)) => {
+				while chars.next_if(|&c| is_ident(c)).is_some() {}
+				out.push_str(":?");
+			}
+			// Whitespace is collapsed to a single space
+			c if c.is_whitespace() => {
+				if last.map_or(false, |c| !c.is_whitespace()) {
+					out.push(' ');
+				}
+			}
+			c => out.push(c),
+		}
+	}
+	out.trim_end().to_owned()
+}
+
 pub fn query(i: &str) -> IResult<&str, Query> {
 	let (i, v) = all_consuming(statements)(i)?;
 	Ok((i, Query(v)))
@@ -79,6 +198,57 @@ mod tests {
 		assert_eq!("CREATE test;\nCREATE temp;", format!("{}", out))
 	}
 
+	#[test]
+	fn normalize_query() {
+		let sql = "SELECT * FROM person:tobie WHERE name = 'Tobie' AND age > 30 LIMIT 10; SELECT * FROM type::thing('person', 1)";
+		let out = query(sql).unwrap().1;
+		assert_eq!(
+			"SELECT * FROM person:? WHERE name = ? AND age > ? LIMIT ?; SELECT * FROM type::thing(?, ?);",
+			out.normalize()
+		);
+	}
+
+	#[test]
+	fn normalize_query_escaped_record_id() {
+		let one = query("SELECT * FROM person:⟨tobie smith⟩").unwrap().1;
+		let two = query("SELECT * FROM person:tobie").unwrap().1;
+		assert_eq!("SELECT * FROM person:?;", one.normalize());
+		assert_eq!(one.normalize(), two.normalize());
+	}
+
+	#[test]
+	fn normalize_query_negative_number() {
+		let one = query("SELECT * FROM person WHERE age > -1 AND score = -1.5").unwrap().1;
+		let two = query("SELECT * FROM person WHERE age > 1 AND score = 1.5").unwrap().1;
+		assert_eq!("SELECT * FROM person WHERE age > ? AND score = ?;", one.normalize());
+		assert_eq!(one.normalize(), two.normalize());
+		// Subtraction is kept as an operator
+		let sql = query("SELECT * FROM person WHERE age - 1 > 0").unwrap().1;
+		assert_eq!("SELECT * FROM person WHERE age - ? > ?;", sql.normalize());
+	}
+
+	#[test]
+	fn normalize_query_array() {
+		let one = query("SELECT * FROM person WHERE age IN [1, 2]").unwrap().1;
+		let two = query("SELECT * FROM person WHERE age IN [1, 2, 3]").unwrap().1;
+		let three = query("SELECT * FROM person WHERE age IN [[1], ['a', 'b'], []]").unwrap().1;
+		assert_eq!("SELECT * FROM person WHERE age IN ?;", one.normalize());
+		assert_eq!(one.normalize(), two.normalize());
+		assert_eq!(one.normalize(), three.normalize());
+		// Arrays of fields, and idiom parts, are kept
+		let sql = query("SELECT * FROM person WHERE tags[0] IN [name, 1]").unwrap().1;
+		assert_eq!("SELECT * FROM person WHERE tags[?] IN [name, ?];", sql.normalize());
+	}
+
+	#[test]
+	fn fingerprint_query() {
+		let one = query("SELECT * FROM person WHERE name = 'Tobie' AND age > 30").unwrap().1;
+		let two = query("SELECT  *  FROM person\nWHERE name = \"Jaime\" AND age > 7").unwrap().1;
+		let three = query("SELECT * FROM person WHERE name = 'Tobie' OR age > 30").unwrap().1;
+		assert_eq!(one.fingerprint(), two.fingerprint());
+		assert_ne!(one.fingerprint(), three.fingerprint());
+	}
+
 	#[test]
 	fn multiple_query_semicolons_multi_comments() {
 		let sql = "CREATE test;;;CREATE temp;;;/* some comment */;;;/* other comment */";
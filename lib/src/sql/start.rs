@@ -5,7 +5,9 @@ use crate::err::Error;
 use crate::sql::comment::shouldbespace;
 use crate::sql::error::IResult;
 use crate::sql::number::Number;
+use crate::sql::thing::Thing;
 use crate::sql::value::{value, Value};
+use nom::branch::alt;
 use nom::bytes::complete::tag_no_case;
 use nom::combinator::opt;
 use nom::sequence::tuple;
@@ -15,6 +17,13 @@ use std::fmt;
 #[derive(Clone, Debug, Default, Eq, PartialEq, PartialOrd, Serialize, Deserialize, Hash)]
 pub struct Start(pub Value);
 
+pub(crate) enum Position {
+	// Skip a number of results
+	Offset(usize),
+	// Resume after a record, given the values it was ordered by, and its id
+	After(Vec<Value>, Thing),
+}
+
 impl Start {
 	pub(crate) async fn process(
 		&self,
@@ -22,10 +31,20 @@ impl Start {
 		opt: &Options,
 		txn: &Transaction,
 		doc: Option<&CursorDoc<'_>>,
-	) -> Result<usize, Error> {
+	) -> Result<Position, Error> {
 		match self.0.compute(ctx, opt, txn, doc).await {
 			// This is a valid starting number
-			Ok(Value::Number(Number::Int(v))) if v >= 0 => Ok(v as usize),
+			Ok(Value::Number(Number::Int(v))) if v >= 0 => Ok(Position::Offset(v as usize)),
+			// This is a valid resume cursor
+			Ok(Value::Thing(v)) => Ok(Position::After(vec![], v)),
+			// This is a valid resume cursor with the ordered values
+			Ok(Value::Array(v)) if matches!(v.last(), Some(Value::Thing(_))) => {
+				let mut v = v.0;
+				match v.pop() {
+					Some(Value::Thing(id)) => Ok(Position::After(v, id)),
+					_ => Err(Error::Unreachable),
+				}
+			}
 			// An invalid value was specified
 			Ok(v) => Err(Error::InvalidStart {
 				value: v.as_string(),
@@ -38,13 +57,19 @@ impl Start {
 
 impl fmt::Display for Start {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "START {}", self.0)
+		match &self.0 {
+			Value::Thing(_) => write!(f, "START AFTER {}", self.0),
+			Value::Array(v) if matches!(v.last(), Some(Value::Thing(_))) => {
+				write!(f, "START AFTER {}", self.0)
+			}
+			_ => write!(f, "START {}", self.0),
+		}
 	}
 }
 
 pub fn start(i: &str) -> IResult<&str, Start> {
 	let (i, _) = tag_no_case("START")(i)?;
-	let (i, _) = opt(tuple((shouldbespace, tag_no_case("AT"))))(i)?;
+	let (i, _) = opt(tuple((shouldbespace, alt((tag_no_case("AFTER"), tag_no_case("AT"))))))(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, v) = value(i)?;
 	Ok((i, Start(v)))
@@ -74,4 +99,23 @@ mod tests {
 		assert_eq!(out, Start(Value::from(100)));
 		assert_eq!("START 100", format!("{}", out));
 	}
+
+	#[test]
+	fn start_statement_after() {
+		let sql = "START AFTER person:test";
+		let res = start(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(out, Start(Value::from(Thing::from(("person", "test")))));
+		assert_eq!("START AFTER person:test", format!("{}", out));
+	}
+
+	#[test]
+	fn start_statement_after_ordered() {
+		let sql = "START AFTER [30, person:test]";
+		let res = start(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!("START AFTER [30, person:test]", format!("{}", out));
+	}
 }
@@ -27,6 +27,7 @@ use crate::sql::statements::select::{select, SelectStatement};
 use crate::sql::statements::set::{set, SetStatement};
 use crate::sql::statements::show::{show, ShowStatement};
 use crate::sql::statements::sleep::{sleep, SleepStatement};
+use crate::sql::statements::suggest::{suggest, SuggestStatement};
 use crate::sql::statements::update::{update, UpdateStatement};
 use crate::sql::statements::yuse::{yuse, UseStatement};
 use crate::sql::value::Value;
@@ -98,6 +99,7 @@ pub enum Statement {
 	Sleep(SleepStatement),
 	Update(UpdateStatement),
 	Use(UseStatement),
+	Suggest(SuggestStatement),
 }
 
 impl Statement {
@@ -116,7 +118,7 @@ impl Statement {
 	/// Check if we require a writeable transaction
 	pub(crate) fn writeable(&self) -> bool {
 		match self {
-			Self::Analyze(_) => false,
+			Self::Analyze(v) => v.writeable(),
 			Self::Create(v) => v.writeable(),
 			Self::Define(_) => true,
 			Self::Delete(v) => v.writeable(),
@@ -135,6 +137,7 @@ impl Statement {
 			Self::Sleep(_) => false,
 			Self::Update(v) => v.writeable(),
 			Self::Use(_) => false,
+			Self::Suggest(_) => false,
 			_ => unreachable!(),
 		}
 	}
@@ -161,9 +164,10 @@ impl Statement {
 			Self::Remove(v) => v.compute(ctx, opt, txn, doc).await,
 			Self::Select(v) => v.compute(ctx, opt, txn, doc).await,
 			Self::Set(v) => v.compute(ctx, opt, txn, doc).await,
-			Self::Show(v) => v.compute(ctx, opt, doc).await,
+			Self::Show(v) => v.compute(ctx, opt, txn, doc).await,
 			Self::Sleep(v) => v.compute(ctx, opt, doc).await,
 			Self::Update(v) => v.compute(ctx, opt, txn, doc).await,
+			Self::Suggest(v) => v.compute(ctx, opt, txn, doc).await,
 			_ => unreachable!(),
 		}
 	}
@@ -194,6 +198,7 @@ impl Display for Statement {
 			Self::Sleep(v) => write!(Pretty::from(f), "{v}"),
 			Self::Update(v) => write!(Pretty::from(f), "{v}"),
 			Self::Use(v) => write!(Pretty::from(f), "{v}"),
+			Self::Suggest(v) => write!(Pretty::from(f), "{v}"),
 		}
 	}
 }
@@ -222,7 +227,11 @@ pub fn statement(i: &str) -> IResult<&str, Statement> {
 			map(set, Statement::Set),
 			map(show, Statement::Show),
 			map(sleep, Statement::Sleep),
-			alt((map(update, Statement::Update), map(yuse, Statement::Use))),
+			alt((
+				map(update, Statement::Update),
+				map(yuse, Statement::Use),
+				map(suggest, Statement::Suggest),
+			)),
 		)),
 		mightbespace,
 	)(i)
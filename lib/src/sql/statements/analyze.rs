@@ -5,13 +5,16 @@ use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::idx::btree::store::BTreeStoreType;
 use crate::idx::ft::FtIndex;
+use crate::idx::hll::HyperLogLog;
 use crate::idx::IndexKeyBase;
 use crate::sql::comment::shouldbespace;
 use crate::sql::error::IResult;
 use crate::sql::ident::{ident, Ident};
 use crate::sql::index::Index;
+use crate::sql::object::Object;
 use crate::sql::value::Value;
 use derive::Store;
+use nom::branch::alt;
 use nom::bytes::complete::tag_no_case;
 use serde::{Deserialize, Serialize};
 use std::fmt;
@@ -20,9 +23,14 @@ use std::fmt::{Display, Formatter};
 #[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize, Store, Hash)]
 pub enum AnalyzeStatement {
 	Idx(Ident, Ident),
+	Tb(Ident),
 }
 
 impl AnalyzeStatement {
+	/// Check if we require a writeable transaction
+	pub(crate) fn writeable(&self) -> bool {
+		matches!(self, Self::Tb(_))
+	}
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
@@ -62,16 +70,52 @@ impl AnalyzeStatement {
 							BTreeStoreType::Traversal,
 						)
 						.await?;
-						ft.statistics(&mut run).await?
+						Value::from(ft.statistics(&mut run).await?)
 					}
 					_ => {
-						return Err(Error::FeatureNotYetImplemented {
-							feature: "Statistics on unique and non-unique indexes.",
-						})
+						// Sketch the distinct values in the index
+						let mut hll = HyperLogLog::default();
+						let rng = crate::key::index::Index::range(
+							opt.ns(),
+							opt.db(),
+							tb.as_str(),
+							idx.as_str(),
+						);
+						let mut cur = run.cursor(rng);
+						while let Some((k, _)) = cur.next().await? {
+							let key = crate::key::index::Index::decode(&k)?;
+							hll.add(key.fd.to_string().as_bytes());
+						}
+						let mut res = Object::default();
+						res.insert("cardinality".to_owned(), hll.estimate().into());
+						Value::from(res)
 					}
 				};
 				// Return the result object
-				Value::from(stats).ok()
+				stats.ok()
+			}
+			AnalyzeStatement::Tb(tb) => {
+				// Selected DB?
+				opt.needs(Level::Db)?;
+				// Allowed to run?
+				opt.check(Level::Db)?;
+				// Claim transaction
+				let mut run = txn.lock().await;
+				// Sketch the records in the table
+				let mut hll = HyperLogLog::default();
+				let beg = crate::key::thing::prefix(opt.ns(), opt.db(), tb.as_str());
+				let end = crate::key::thing::suffix(opt.ns(), opt.db(), tb.as_str());
+				let mut cur = run.cursor(beg..end);
+				while let Some((k, _)) = cur.next().await? {
+					hll.add(&k);
+				}
+				// Store the sketch for count::estimate
+				let key = crate::key::hc::new(opt.ns(), opt.db(), tb.as_str());
+				hll.save(&mut run, key.into()).await?;
+				// Return the result object
+				let mut res = Object::default();
+				res.insert("cardinality".to_owned(), hll.estimate().into());
+				Value::from(res).ok()
 			}
 		}
 	}
@@ -80,6 +124,10 @@ impl AnalyzeStatement {
 pub fn analyze(i: &str) -> IResult<&str, AnalyzeStatement> {
 	let (i, _) = tag_no_case("ANALYZE")(i)?;
 	let (i, _) = shouldbespace(i)?;
+	alt((analyze_index, analyze_table))(i)
+}
+
+fn analyze_index(i: &str) -> IResult<&str, AnalyzeStatement> {
 	let (i, _) = tag_no_case("INDEX")(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, idx) = ident(i)?;
@@ -90,10 +138,18 @@ pub fn analyze(i: &str) -> IResult<&str, AnalyzeStatement> {
 	Ok((i, AnalyzeStatement::Idx(tb, idx)))
 }
 
+fn analyze_table(i: &str) -> IResult<&str, AnalyzeStatement> {
+	let (i, _) = tag_no_case("TABLE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, tb) = ident(i)?;
+	Ok((i, AnalyzeStatement::Tb(tb)))
+}
+
 impl Display for AnalyzeStatement {
 	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
 		match self {
 			Self::Idx(tb, idx) => write!(f, "ANALYZE INDEX {idx} ON {tb}"),
+			Self::Tb(tb) => write!(f, "ANALYZE TABLE {tb}"),
 		}
 	}
 }
@@ -112,4 +168,14 @@ mod tests {
 		assert_eq!(out, AnalyzeStatement::Idx(Ident::from("my_table"), Ident::from("my_index")));
 		assert_eq!("ANALYZE INDEX my_index ON my_table", format!("{}", out));
 	}
+
+	#[test]
+	fn analyze_table() {
+		let sql = "ANALYZE TABLE my_table";
+		let res = analyze(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(out, AnalyzeStatement::Tb(Ident::from("my_table")));
+		assert_eq!("ANALYZE TABLE my_table", format!("{}", out));
+	}
 }
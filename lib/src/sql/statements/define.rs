@@ -20,6 +20,7 @@ use crate::sql::idiom;
 use crate::sql::idiom::{Idiom, Idioms};
 use crate::sql::index::Index;
 use crate::sql::kind::{kind, Kind};
+use crate::sql::number::integer;
 use crate::sql::permission::{permissions, Permissions};
 use crate::sql::statements::{RemoveIndexStatement, UpdateStatement};
 use crate::sql::strand::strand_raw;
@@ -58,6 +59,7 @@ pub enum DefineStatement {
 	Event(DefineEventStatement),
 	Field(DefineFieldStatement),
 	Index(DefineIndexStatement),
+	Sequence(DefineSequenceStatement),
 }
 
 impl DefineStatement {
@@ -82,6 +84,7 @@ impl DefineStatement {
 			Self::Field(ref v) => v.compute(ctx, opt, txn, doc).await,
 			Self::Index(ref v) => v.compute(ctx, opt, txn, doc).await,
 			Self::Analyzer(ref v) => v.compute(ctx, opt, txn, doc).await,
+			Self::Sequence(ref v) => v.compute(ctx, opt, txn, doc).await,
 		}
 	}
 }
@@ -101,6 +104,7 @@ impl Display for DefineStatement {
 			Self::Field(v) => Display::fmt(v, f),
 			Self::Index(v) => Display::fmt(v, f),
 			Self::Analyzer(v) => Display::fmt(v, f),
+			Self::Sequence(v) => Display::fmt(v, f),
 		}
 	}
 }
@@ -114,6 +118,7 @@ pub fn define(i: &str) -> IResult<&str, DefineStatement> {
 		map(token, DefineStatement::Token),
 		map(scope, DefineStatement::Scope),
 		map(param, DefineStatement::Param),
+		map(sequence, DefineStatement::Sequence),
 		map(table, DefineStatement::Table),
 		map(event, DefineStatement::Event),
 		map(field, DefineStatement::Field),
@@ -829,6 +834,98 @@ fn param(i: &str) -> IResult<&str, DefineParamStatement> {
 // --------------------------------------------------
 // --------------------------------------------------
 
+#[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize, Store, Hash)]
+pub struct DefineSequenceStatement {
+	pub name: Ident,
+	pub start: i64,
+	pub restart: bool,
+}
+
+impl Default for DefineSequenceStatement {
+	fn default() -> Self {
+		Self {
+			name: Ident::default(),
+			start: 1,
+			restart: false,
+		}
+	}
+}
+
+impl DefineSequenceStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Claim transaction
+		let mut run = txn.lock().await;
+		// Process the statement
+		let key = crate::key::sq::new(opt.ns(), opt.db(), &self.name);
+		let new = !run.exi(key.clone()).await?;
+		run.add_ns(opt.ns(), opt.strict).await?;
+		run.add_db(opt.ns(), opt.db(), opt.strict).await?;
+		run.set(key, self).await?;
+		// Restart the sequence
+		if new || self.restart {
+			let key = crate::key::sn::new(opt.ns(), opt.db(), &self.name);
+			run.del(key).await?;
+			// Discard any values which were already allocated
+			run.restart_sn(opt.ns(), opt.db(), &self.name);
+		}
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl Display for DefineSequenceStatement {
+	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
+		write!(f, "DEFINE SEQUENCE {} START {}", self.name, self.start)?;
+		if self.restart {
+			write!(f, " RESTART")?
+		}
+		Ok(())
+	}
+}
+
+fn sequence(i: &str) -> IResult<&str, DefineSequenceStatement> {
+	let (i, _) = tag_no_case("DEFINE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("SEQUENCE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, name) = ident(i)?;
+	let (i, start) = opt(|i| {
+		let (i, _) = shouldbespace(i)?;
+		let (i, _) = tag_no_case("START")(i)?;
+		let (i, _) = shouldbespace(i)?;
+		let (i, v) = integer(i)?;
+		Ok((i, v))
+	})(i)?;
+	let (i, restart) = opt(|i| {
+		let (i, _) = shouldbespace(i)?;
+		let (i, _) = tag_no_case("RESTART")(i)?;
+		Ok((i, true))
+	})(i)?;
+	Ok((
+		i,
+		DefineSequenceStatement {
+			name,
+			start: start.unwrap_or(1),
+			restart: restart.unwrap_or(false),
+		},
+	))
+}
+
+// --------------------------------------------------
+// --------------------------------------------------
+// --------------------------------------------------
+
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store, Hash)]
 pub struct DefineTableStatement {
 	pub name: Ident,
@@ -837,6 +934,7 @@ pub struct DefineTableStatement {
 	pub view: Option<View>,
 	pub permissions: Permissions,
 	pub changefeed: Option<ChangeFeed>,
+	pub expire: Option<Duration>,
 }
 
 impl DefineTableStatement {
@@ -923,6 +1021,9 @@ impl Display for DefineTableStatement {
 		if let Some(ref cf) = self.changefeed {
 			write!(f, " CHANGEFEED {}", crate::sql::duration::Duration(cf.expiry))?;
 		}
+		if let Some(ref v) = self.expire {
+			write!(f, " EXPIRE {v}")?
+		}
 		Ok(())
 	}
 }
@@ -968,6 +1069,10 @@ fn table(i: &str) -> IResult<&str, DefineTableStatement> {
 				DefineTableOption::ChangeFeed(ref v) => Some(v.to_owned()),
 				_ => None,
 			}),
+			expire: opts.iter().find_map(|x| match x {
+				DefineTableOption::Expire(ref v) => Some(v.to_owned()),
+				_ => None,
+			}),
 		},
 	))
 }
@@ -980,6 +1085,7 @@ pub enum DefineTableOption {
 	Schemafull,
 	Permissions(Permissions),
 	ChangeFeed(ChangeFeed),
+	Expire(Duration),
 }
 
 fn table_opts(i: &str) -> IResult<&str, DefineTableOption> {
@@ -990,6 +1096,7 @@ fn table_opts(i: &str) -> IResult<&str, DefineTableOption> {
 		table_schemafull,
 		table_permissions,
 		table_changefeed,
+		table_expire,
 	))(i)
 }
 
@@ -1005,6 +1112,14 @@ fn table_changefeed(i: &str) -> IResult<&str, DefineTableOption> {
 	Ok((i, DefineTableOption::ChangeFeed(v)))
 }
 
+fn table_expire(i: &str) -> IResult<&str, DefineTableOption> {
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("EXPIRE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, v) = duration(i)?;
+	Ok((i, DefineTableOption::Expire(v)))
+}
+
 fn table_view(i: &str) -> IResult<&str, DefineTableOption> {
 	let (i, _) = shouldbespace(i)?;
 	let (i, v) = view(i)?;
@@ -1384,6 +1499,26 @@ mod tests {
 		assert_eq!(6, stm.to_vec().len());
 	}
 
+	#[test]
+	fn check_define_sequence() {
+		let sql = "DEFINE SEQUENCE person START 100";
+		let (_, seq) = sequence(sql).unwrap();
+		assert_eq!(
+			seq,
+			DefineSequenceStatement {
+				name: Ident("person".to_string()),
+				start: 100,
+				restart: false,
+			}
+		);
+		assert_eq!(seq.to_string(), "DEFINE SEQUENCE person START 100");
+		let (_, seq) = sequence("DEFINE SEQUENCE person").unwrap();
+		assert_eq!(seq.start, 1);
+		let (_, seq) = sequence("DEFINE SEQUENCE person START 5 RESTART").unwrap();
+		assert!(seq.restart);
+		assert_eq!(seq.to_string(), "DEFINE SEQUENCE person START 5 RESTART");
+	}
+
 	#[test]
 	fn check_create_non_unique_index() {
 		let sql = "DEFINE INDEX my_index ON TABLE my_table COLUMNS my_col";
@@ -1490,4 +1625,14 @@ mod tests {
 		let deserializled = DefineTableStatement::try_from(&serialized).unwrap();
 		assert_eq!(out, deserializled);
 	}
+
+	#[test]
+	fn define_table_with_expire() {
+		let sql = "DEFINE TABLE mytable SCHEMALESS EXPIRE 1d";
+		let res = table(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(out.expire, Some(Duration::from_secs(86400)));
+		assert_eq!(sql, format!("{}", out));
+	}
 }
@@ -4,6 +4,7 @@ use crate::dbs::{Level, Transaction};
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::comment::shouldbespace;
+use crate::sql::duration::Duration;
 use crate::sql::error::IResult;
 use crate::sql::ident::{ident, Ident};
 use crate::sql::object::Object;
@@ -27,7 +28,7 @@ impl InfoStatement {
 	/// Process this type returning a computed simple Value
 	pub(crate) async fn compute(
 		&self,
-		_ctx: &Context<'_>,
+		ctx: &Context<'_>,
 		opt: &Options,
 		txn: &Transaction,
 		_doc: Option<&CursorDoc<'_>>,
@@ -79,6 +80,16 @@ impl InfoStatement {
 					tmp.insert(v.name.to_string(), v.to_string().into());
 				}
 				res.insert("tokens".to_owned(), tmp.into());
+				// Process the resource usage
+				if let Some(ds) = ctx.datastore() {
+					let usage = ds.ns_usage(opt.ns());
+					let mut tmp = Object::default();
+					tmp.insert("time".to_owned(), Duration::from(usage.time).into());
+					tmp.insert("reads".to_owned(), usage.reads.into());
+					tmp.insert("writes".to_owned(), usage.writes.into());
+					tmp.insert("bytes".to_owned(), usage.bytes.into());
+					res.insert("usage".to_owned(), tmp.into());
+				}
 				// Ok all good
 				Value::from(res).ok()
 			}
@@ -115,6 +126,12 @@ impl InfoStatement {
 					tmp.insert(v.name.to_string(), v.to_string().into());
 				}
 				res.insert("params".to_owned(), tmp.into());
+				// Process the sequences
+				let mut tmp = Object::default();
+				for v in run.all_sq(opt.ns(), opt.db()).await?.iter() {
+					tmp.insert(v.name.to_string(), v.to_string().into());
+				}
+				res.insert("sequences".to_owned(), tmp.into());
 				// Process the scopes
 				let mut tmp = Object::default();
 				for v in run.all_sc(opt.ns(), opt.db()).await?.iter() {
@@ -18,6 +18,7 @@ pub(crate) mod select;
 pub(crate) mod set;
 pub(crate) mod show;
 pub(crate) mod sleep;
+pub(crate) mod suggest;
 pub(crate) mod update;
 pub(crate) mod yuse;
 
@@ -49,6 +50,7 @@ pub use self::define::DefineLoginStatement;
 pub use self::define::DefineNamespaceStatement;
 pub use self::define::DefineParamStatement;
 pub use self::define::DefineScopeStatement;
+pub use self::define::DefineSequenceStatement;
 pub use self::define::DefineStatement;
 pub use self::define::DefineTableStatement;
 pub use self::define::DefineTokenStatement;
@@ -62,6 +64,7 @@ pub use self::remove::RemoveLoginStatement;
 pub use self::remove::RemoveNamespaceStatement;
 pub use self::remove::RemoveParamStatement;
 pub use self::remove::RemoveScopeStatement;
+pub use self::remove::RemoveSequenceStatement;
 pub use self::remove::RemoveStatement;
 pub use self::remove::RemoveTableStatement;
 pub use self::remove::RemoveTokenStatement;
@@ -36,6 +36,7 @@ pub enum RemoveStatement {
 	Event(RemoveEventStatement),
 	Field(RemoveFieldStatement),
 	Index(RemoveIndexStatement),
+	Sequence(RemoveSequenceStatement),
 }
 
 impl RemoveStatement {
@@ -47,6 +48,10 @@ impl RemoveStatement {
 		txn: &Transaction,
 		_doc: Option<&CursorDoc<'_>>,
 	) -> Result<Value, Error> {
+		// Check that REMOVE statements are allowed
+		if !ctx.capabilities().allows_remove() {
+			return Err(Error::RemoveDisabled);
+		}
 		match self {
 			Self::Namespace(ref v) => v.compute(ctx, opt, txn).await,
 			Self::Database(ref v) => v.compute(ctx, opt, txn).await,
@@ -60,6 +65,7 @@ impl RemoveStatement {
 			Self::Field(ref v) => v.compute(ctx, opt, txn).await,
 			Self::Index(ref v) => v.compute(ctx, opt, txn).await,
 			Self::Analyzer(ref v) => v.compute(ctx, opt, txn).await,
+			Self::Sequence(ref v) => v.compute(ctx, opt, txn).await,
 		}
 	}
 }
@@ -79,6 +85,7 @@ impl Display for RemoveStatement {
 			Self::Field(v) => Display::fmt(v, f),
 			Self::Index(v) => Display::fmt(v, f),
 			Self::Analyzer(v) => Display::fmt(v, f),
+			Self::Sequence(v) => Display::fmt(v, f),
 		}
 	}
 }
@@ -92,6 +99,7 @@ pub fn remove(i: &str) -> IResult<&str, RemoveStatement> {
 		map(token, RemoveStatement::Token),
 		map(scope, RemoveStatement::Scope),
 		map(param, RemoveStatement::Param),
+		map(sequence, RemoveStatement::Sequence),
 		map(table, RemoveStatement::Table),
 		map(event, RemoveStatement::Event),
 		map(field, RemoveStatement::Field),
@@ -591,6 +599,62 @@ fn param(i: &str) -> IResult<&str, RemoveParamStatement> {
 // --------------------------------------------------
 // --------------------------------------------------
 
+#[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store, Hash)]
+pub struct RemoveSequenceStatement {
+	pub name: Ident,
+}
+
+impl RemoveSequenceStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		_ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+	) -> Result<Value, Error> {
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Claim transaction
+		let mut run = txn.lock().await;
+		// Delete the definition
+		let key = crate::key::sq::new(opt.ns(), opt.db(), &self.name);
+		run.del(key).await?;
+		// Delete the current value
+		let key = crate::key::sn::new(opt.ns(), opt.db(), &self.name);
+		run.del(key).await?;
+		// Discard any values which were already allocated
+		run.restart_sn(opt.ns(), opt.db(), &self.name);
+		// Ok all good
+		Ok(Value::None)
+	}
+}
+
+impl Display for RemoveSequenceStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		write!(f, "REMOVE SEQUENCE {}", self.name)
+	}
+}
+
+fn sequence(i: &str) -> IResult<&str, RemoveSequenceStatement> {
+	let (i, _) = tag_no_case("REMOVE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("SEQUENCE")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, name) = ident(i)?;
+	Ok((
+		i,
+		RemoveSequenceStatement {
+			name,
+		},
+	))
+}
+
+// --------------------------------------------------
+// --------------------------------------------------
+// --------------------------------------------------
+
 #[derive(Clone, Debug, Default, Eq, PartialEq, Serialize, Deserialize, Store, Hash)]
 pub struct RemoveTableStatement {
 	pub name: Ident,
@@ -25,8 +25,9 @@ use crate::sql::timeout::{timeout, Timeout};
 use crate::sql::value::{selects, Value, Values};
 use crate::sql::version::{version, Version};
 use derive::Store;
+use nom::branch::alt;
 use nom::bytes::complete::tag_no_case;
-use nom::combinator::opt;
+use nom::combinator::{map, opt, peek};
 use nom::sequence::preceded;
 use serde::{Deserialize, Serialize};
 use std::fmt;
@@ -46,6 +47,7 @@ pub struct SelectStatement {
 	pub timeout: Option<Timeout>,
 	pub parallel: bool,
 	pub explain: Option<Explain>,
+	pub distinct: bool,
 }
 
 impl SelectStatement {
@@ -142,7 +144,11 @@ impl SelectStatement {
 
 impl fmt::Display for SelectStatement {
 	fn fmt(&self, f: &mut fmt::Formatter) -> fmt::Result {
-		write!(f, "SELECT {} FROM {}", self.expr, self.what)?;
+		f.write_str("SELECT ")?;
+		if self.distinct {
+			f.write_str("DISTINCT ")?
+		}
+		write!(f, "{} FROM {}", self.expr, self.what)?;
 		if let Some(ref v) = self.cond {
 			write!(f, " {v}")?
 		}
@@ -183,7 +189,7 @@ impl fmt::Display for SelectStatement {
 pub fn select(i: &str) -> IResult<&str, SelectStatement> {
 	let (i, _) = tag_no_case("SELECT")(i)?;
 	let (i, _) = shouldbespace(i)?;
-	let (i, expr) = fields(i)?;
+	let (i, (distinct, expr)) = alt((distinct, map(fields, |v| (false, v))))(i)?;
 	let (i, _) = shouldbespace(i)?;
 	let (i, _) = tag_no_case("FROM")(i)?;
 	let (i, _) = shouldbespace(i)?;
@@ -218,10 +224,20 @@ pub fn select(i: &str) -> IResult<&str, SelectStatement> {
 			timeout,
 			parallel: parallel.is_some(),
 			explain,
+			distinct,
 		},
 	))
 }
 
+fn distinct(i: &str) -> IResult<&str, (bool, Fields)> {
+	let (i, _) = tag_no_case("DISTINCT")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, expr) = fields(i)?;
+	// A field which is named distinct is not a DISTINCT clause
+	let (i, _) = peek(preceded(shouldbespace, tag_no_case("FROM")))(i)?;
+	Ok((i, (true, expr)))
+}
+
 #[cfg(test)]
 mod tests {
 
@@ -271,4 +287,24 @@ mod tests {
 		let out = res.unwrap().1;
 		assert_eq!(sql, format!("{}", out))
 	}
+
+	#[test]
+	fn select_statement_distinct() {
+		let sql = "SELECT DISTINCT name, age FROM test";
+		let res = select(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(out.distinct);
+		assert_eq!(sql, format!("{}", out))
+	}
+
+	#[test]
+	fn select_statement_distinct_field() {
+		let sql = "SELECT distinct FROM test";
+		let res = select(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert!(!out.distinct);
+		assert_eq!("SELECT distinct FROM test", format!("{}", out))
+	}
 }
@@ -1,5 +1,5 @@
 use crate::ctx::Context;
-use crate::dbs::Options;
+use crate::dbs::{Level, Options, Transaction};
 use crate::doc::CursorDoc;
 use crate::err::Error;
 use crate::sql::comment::shouldbespace;
@@ -31,12 +31,21 @@ impl ShowStatement {
 	pub(crate) async fn compute(
 		&self,
 		_ctx: &Context<'_>,
-		_opt: &Options,
+		opt: &Options,
+		txn: &Transaction,
 		_doc: Option<&CursorDoc<'_>>,
 	) -> Result<Value, Error> {
-		Err(Error::FeatureNotYetImplemented {
-			feature: "change feed",
-		})
+		// Selected DB?
+		opt.needs(Level::Db)?;
+		// Allowed to run?
+		opt.check(Level::Db)?;
+		// Claim transaction
+		let mut run = txn.lock().await;
+		// Read the changefeed
+		let tb = self.table.as_ref().map(|v| v.0.as_str());
+		let res = crate::cf::read(&mut run, opt.ns(), opt.db(), tb, self.since, self.limit).await?;
+		// Output the change sets
+		Ok(res.into_iter().map(|v| v.into_value()).collect::<Vec<_>>().into())
 	}
 }
 
@@ -0,0 +1,112 @@
+use crate::ctx::Context;
+use crate::dbs::Options;
+use crate::dbs::{Level, Transaction};
+use crate::doc::CursorDoc;
+use crate::err::Error;
+use crate::sql::comment::shouldbespace;
+use crate::sql::error::IResult;
+use crate::sql::idiom::Idioms;
+use crate::sql::index::Index;
+use crate::sql::object::Object;
+use crate::sql::statements::DefineIndexStatement;
+use crate::sql::value::Value;
+use derive::Store;
+use nom::bytes::complete::tag_no_case;
+use serde::{Deserialize, Serialize};
+use std::fmt;
+use std::fmt::{Display, Formatter};
+
+#[derive(Clone, Debug, Eq, PartialEq, Serialize, Deserialize, Store, Hash)]
+pub enum SuggestStatement {
+	Idx,
+}
+
+impl SuggestStatement {
+	/// Process this type returning a computed simple Value
+	pub(crate) async fn compute(
+		&self,
+		ctx: &Context<'_>,
+		opt: &Options,
+		txn: &Transaction,
+		_doc: Option<&CursorDoc<'_>>,
+	) -> Result<Value, Error> {
+		match self {
+			SuggestStatement::Idx => {
+				// Selected DB?
+				opt.needs(Level::Db)?;
+				// Allowed to run?
+				opt.check(Level::Db)?;
+				// Get the recorded full table scans
+				let suggestions = match ctx.datastore() {
+					Some(ds) => ds.suggestions(opt.ns(), opt.db()),
+					None => vec![],
+				};
+				// Claim transaction
+				let mut run = txn.lock().await;
+				// Create the result set
+				let mut res = Vec::new();
+				for v in suggestions {
+					// Skip fields which have been indexed since
+					let ix = run.all_ix(opt.ns(), opt.db(), &v.tb).await?;
+					if ix.iter().any(|ix| ix.cols.len() == 1 && ix.cols[0] == v.field) {
+						continue;
+					}
+					// Create the index definition
+					let name = format!("{}_{}", v.tb, v.field)
+						.chars()
+						.map(|c| match c.is_ascii_alphanumeric() {
+							true => c,
+							false => '_',
+						})
+						.collect::<String>();
+					let def = DefineIndexStatement {
+						name: name.into(),
+						what: v.tb.clone().into(),
+						cols: Idioms(vec![v.field.clone()]),
+						index: Index::Idx,
+					};
+					// Output the suggestion
+					let mut tmp = Object::default();
+					tmp.insert("table".to_owned(), v.tb.into());
+					tmp.insert("field".to_owned(), v.field.to_string().into());
+					tmp.insert("scans".to_owned(), v.scans.into());
+					tmp.insert("statement".to_owned(), def.to_string().into());
+					res.push(Value::from(tmp));
+				}
+				// Return the suggestions
+				Value::from(res).ok()
+			}
+		}
+	}
+}
+
+pub fn suggest(i: &str) -> IResult<&str, SuggestStatement> {
+	let (i, _) = tag_no_case("SUGGEST")(i)?;
+	let (i, _) = shouldbespace(i)?;
+	let (i, _) = tag_no_case("INDEX")(i)?;
+	Ok((i, SuggestStatement::Idx))
+}
+
+impl Display for SuggestStatement {
+	fn fmt(&self, f: &mut Formatter) -> fmt::Result {
+		match self {
+			Self::Idx => write!(f, "SUGGEST INDEX"),
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+
+	#[test]
+	fn suggest_index() {
+		let sql = "SUGGEST INDEX";
+		let res = suggest(sql);
+		assert!(res.is_ok());
+		let out = res.unwrap().1;
+		assert_eq!(out, SuggestStatement::Idx);
+		assert_eq!("SUGGEST INDEX", format!("{}", out));
+	}
+}
@@ -0,0 +1,150 @@
+use crate::err::Error;
+use crate::sql::array::Array;
+use crate::sql::id::Id;
+use crate::sql::number::Number;
+use crate::sql::object::Object;
+use crate::sql::thing::Thing;
+use crate::sql::value::Value;
+use rust_decimal::prelude::ToPrimitive;
+use rust_decimal::Decimal;
+use sha2::{Digest, Sha256};
+use std::str::FromStr;
+
+impl Value {
+	/// Converts this value into its canonical form, so that equal documents
+	/// always serialize to identical bytes. Numbers which are equal are given
+	/// the same representation, NaN floats are unified, object fields set to
+	/// NONE are removed, and record ids are canonicalized.
+	pub fn canonical(self) -> Value {
+		match self {
+			Value::Number(v) => Value::Number(v.canonical()),
+			Value::Thing(v) => Value::Thing(v.canonical()),
+			Value::Array(v) => Value::Array(v.canonical()),
+			Value::Object(v) => Value::Object(v.canonical()),
+			v => v,
+		}
+	}
+	/// Serializes the canonical form of this value
+	pub fn to_canonical_bytes(&self) -> Result<Vec<u8>, Error> {
+		Ok(crate::sql::serde::serialize(&self.clone().canonical())?)
+	}
+	/// Returns a hex encoded SHA-256 hash of the canonical form of this value
+	pub fn canonical_hash(&self) -> Result<String, Error> {
+		let mut hasher = Sha256::new();
+		hasher.update(self.to_canonical_bytes()?);
+		let val = hasher.finalize();
+		Ok(format!("{val:x}"))
+	}
+}
+
+impl Number {
+	/// Converts this number into its canonical form. Whole numbers are
+	/// stored as integers, and decimals which can be represented exactly
+	/// as a float are stored as floats.
+	fn canonical(self) -> Number {
+		match self {
+			Number::Float(v) if v.is_nan() => Number::Float(f64::NAN),
+			Number::Float(v) if v.fract() == 0.0 && v >= i64::MIN as f64 && v < i64::MAX as f64 => {
+				Number::Int(v as i64)
+			}
+			Number::Decimal(v) => {
+				let v = v.normalize();
+				if v.fract().is_zero() {
+					if let Some(i) = v.to_i64() {
+						return Number::Int(i);
+					}
+				}
+				match v.to_f64() {
+					Some(f) if Decimal::from_str(&f.to_string()) == Ok(v) => Number::Float(f),
+					_ => Number::Decimal(v),
+				}
+			}
+			v => v,
+		}
+	}
+}
+
+impl Array {
+	fn canonical(self) -> Array {
+		Array(self.0.into_iter().map(Value::canonical).collect())
+	}
+}
+
+impl Object {
+	fn canonical(self) -> Object {
+		Object(
+			self.0
+				.into_iter()
+				.filter(|(_, v)| !v.is_none())
+				.map(|(k, v)| (k, v.canonical()))
+				.collect(),
+		)
+	}
+}
+
+impl Thing {
+	fn canonical(self) -> Thing {
+		Thing {
+			tb: self.tb,
+			id: match self.id {
+				Id::Array(v) => Id::Array(v.canonical()),
+				Id::Object(v) => Id::Object(v.canonical()),
+				v => v,
+			},
+		}
+	}
+}
+
+#[cfg(test)]
+mod tests {
+
+	use super::*;
+	use crate::sql::test::Parse;
+
+	#[test]
+	fn canonical_object_fields() {
+		let one = Value::parse("{ b: 2, a: { d: [1, 2], c: NONE }, e: 'text' }");
+		let two = Value::parse("{ e: 'text', a: { d: [1, 2] }, b: 2 }");
+		assert_eq!(one.to_canonical_bytes().unwrap(), two.to_canonical_bytes().unwrap());
+		assert_eq!(one.canonical_hash().unwrap(), two.canonical_hash().unwrap());
+	}
+
+	#[test]
+	fn canonical_numbers() {
+		let one = Value::from(Decimal::from_str("1.500").unwrap());
+		let two = Value::from(Decimal::from_str("1.5").unwrap());
+		assert_eq!(one.to_canonical_bytes().unwrap(), two.to_canonical_bytes().unwrap());
+		let one = Value::from(-0.0);
+		let two = Value::from(0.0);
+		assert_eq!(one.to_canonical_bytes().unwrap(), two.to_canonical_bytes().unwrap());
+	}
+
+	#[test]
+	fn canonical_number_types() {
+		let int = Value::from(1);
+		let float = Value::from(1.0);
+		let decimal = Value::from(Decimal::from(1));
+		assert_eq!(int.to_canonical_bytes().unwrap(), float.to_canonical_bytes().unwrap());
+		assert_eq!(int.to_canonical_bytes().unwrap(), decimal.to_canonical_bytes().unwrap());
+		let float = Value::from(1.5);
+		let decimal = Value::from(Decimal::from_str("1.50").unwrap());
+		assert_eq!(float.to_canonical_bytes().unwrap(), decimal.to_canonical_bytes().unwrap());
+		let one = Value::from(Decimal::from_str("0.1000000000000000000001").unwrap());
+		let two = Value::from(0.1);
+		assert_ne!(one.to_canonical_bytes().unwrap(), two.to_canonical_bytes().unwrap());
+	}
+
+	#[test]
+	fn canonical_record_ids() {
+		let one = Value::parse("{ id: person:[1, { b: 2, a: 1.0 }], link: person:{ x: 1.0 } }");
+		let two = Value::parse("{ link: person:{ x: 1 }, id: person:[1.0, { a: 1, b: 2 }] }");
+		assert_eq!(one.to_canonical_bytes().unwrap(), two.to_canonical_bytes().unwrap());
+	}
+
+	#[test]
+	fn canonical_different() {
+		let one = Value::parse("{ a: 1, b: [1, 2] }");
+		let two = Value::parse("{ a: 1, b: [2, 1] }");
+		assert_ne!(one.canonical_hash().unwrap(), two.canonical_hash().unwrap());
+	}
+}
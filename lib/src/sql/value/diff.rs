@@ -50,15 +50,16 @@ impl Value {
 					}
 					n += 1;
 				}
-				while n < a.len() {
-					if n >= b.len() {
-						ops.push(Operation {
-							op: Op::Remove,
-							path: path.clone().push(n.into()),
-							value: Value::Null,
-						})
-					}
-					n += 1;
+				// Remove trailing items from the end, so that
+				// each index is still valid when it is applied
+				let mut n = a.len();
+				while n > b.len() {
+					n -= 1;
+					ops.push(Operation {
+						op: Op::Remove,
+						path: path.clone().push(n.into()),
+						value: Value::Null,
+					})
 				}
 			}
 			(Value::Strand(a), Value::Strand(b)) if a != b => ops.push(Operation {
@@ -137,4 +138,31 @@ mod tests {
 		);
 		assert_eq!(res.to_operations().unwrap(), old.diff(&now, Idiom::default()));
 	}
+
+	#[test]
+	fn diff_remove_array() {
+		let old = Value::parse("{ test: [1,2,3,4,5] }");
+		let now = Value::parse("{ test: [1,2,3] }");
+		let res =
+			Value::parse("[{ op: 'remove', path: '/test/4' }, { op: 'remove', path: '/test/3' }]");
+		assert_eq!(res.to_operations().unwrap(), old.diff(&now, Idiom::default()));
+	}
+
+	#[test]
+	fn diff_patch_roundtrip() {
+		let tests = [
+			("{ test: [1,2,3,4,5] }", "{ test: [1,2,3] }"),
+			("{ test: [1,2,3] }", "{ test: [1,2,3,4,5] }"),
+			("{ test: [1,2,3] }", "{ test: [3,2] }"),
+			("{ test: { a: [{ b: 1 }] } }", "{ test: { a: [{ b: 2 }, { c: 3 }] }, other: 'text' }"),
+			("{ test: 'some text', other: true }", "{ test: 'some next' }"),
+		];
+		for (old, now) in tests {
+			let mut old = Value::parse(old);
+			let now = Value::parse(now);
+			let ops = Value::from(old.diff(&now, Idiom::default()));
+			old.patch(ops).unwrap();
+			assert_eq!(old, now);
+		}
+	}
 }
@@ -1,4 +1,5 @@
 use crate::err::Error;
+use crate::sql::object::Object;
 use crate::sql::value::Value;
 
 impl Value {
@@ -13,6 +14,29 @@ impl Value {
 		}
 		Ok(())
 	}
+	/// Applies a JSON Merge Patch (RFC 7386) to this value. Fields which
+	/// are set to `null` in the patch are removed, nested objects are
+	/// merged recursively, and any other patch value replaces the target.
+	pub(crate) fn merge_patch(&mut self, val: Value) {
+		match val {
+			Value::Object(patch) => {
+				if !self.is_object() {
+					*self = Value::Object(Object::default());
+				}
+				if let Value::Object(obj) = self {
+					for (k, v) in patch.0 {
+						match v {
+							Value::Null | Value::None => {
+								obj.remove(&k);
+							}
+							v => obj.entry(k).or_insert(Value::None).merge_patch(v),
+						}
+					}
+				}
+			}
+			v => *self = v,
+		}
+	}
 }
 
 #[cfg(test)]
@@ -79,4 +103,57 @@ mod tests {
 		res.merge(mrg).unwrap();
 		assert_eq!(res, val);
 	}
+
+	#[tokio::test]
+	async fn merge_patch_basic() {
+		let mut res = Value::parse(
+			"{
+				title: 'Goodbye!',
+				author: {
+					given: 'John',
+					family: 'Doe',
+				},
+				tags: ['example', 'sample'],
+				content: 'This will be unchanged',
+			}",
+		);
+		let mrg = Value::parse(
+			"{
+				title: 'Hello!',
+				phone: '+01-123-456-7890',
+				author: {
+					family: null,
+				},
+				tags: ['example'],
+			}",
+		);
+		let val = Value::parse(
+			"{
+				title: 'Hello!',
+				author: {
+					given: 'John',
+				},
+				tags: ['example'],
+				content: 'This will be unchanged',
+				phone: '+01-123-456-7890',
+			}",
+		);
+		res.merge_patch(mrg);
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn merge_patch_replace() {
+		let mut res = Value::parse("{ a: { b: 'c' } }");
+		let mrg = Value::parse("{ a: { b: { c: 'd', e: null } } }");
+		let val = Value::parse("{ a: { b: { c: 'd' } } }");
+		res.merge_patch(mrg);
+		assert_eq!(res, val);
+		//
+		let mut res = Value::parse("{ a: 'b' }");
+		let mrg = Value::parse("['c']");
+		let val = Value::parse("['c']");
+		res.merge_patch(mrg);
+		assert_eq!(res, val);
+	}
 }
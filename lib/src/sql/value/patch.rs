@@ -1,15 +1,43 @@
 use crate::err::Error;
 use crate::sql::operation::Op;
+use crate::sql::part::Part;
 use crate::sql::value::Value;
 
 impl Value {
 	pub(crate) fn patch(&mut self, val: Value) -> Result<(), Error> {
 		for o in val.to_operations()?.into_iter() {
 			match o.op {
-				Op::Add => match self.pick(&o.path) {
-					Value::Array(_) => self.inc(&o.path, o.value),
-					_ => self.put(&o.path, o.value),
-				},
+				Op::Add => {
+					// Adding at an array index inserts the value at that position,
+					// and adding at '-' appends the value to the end of the array
+					if let Some((part, parent)) = o.path.split_last() {
+						if let Value::Array(mut v) = self.pick(parent) {
+							let pos = match part {
+								Part::Index(i)
+									if i.is_zero_or_positive() && i.to_usize() <= v.len() =>
+								{
+									Some(i.to_usize())
+								}
+								Part::Index(i) => {
+									return Err(Error::InvalidPatch {
+										message: format!("Array index {i} is out of bounds"),
+									})
+								}
+								Part::Field(f) if f.as_str() == "-" => Some(v.len()),
+								_ => None,
+							};
+							if let Some(pos) = pos {
+								v.insert(pos, o.value);
+								self.put(parent, Value::Array(v));
+								continue;
+							}
+						}
+					}
+					match self.pick(&o.path) {
+						Value::Array(_) => self.inc(&o.path, o.value),
+						_ => self.put(&o.path, o.value),
+					}
+				}
 				Op::Remove => self.cut(&o.path),
 				Op::Replace => self.put(&o.path, o.value),
 				Op::Change => {
@@ -120,6 +148,33 @@ mod tests {
 		assert_eq!(res, val);
 	}
 
+	#[tokio::test]
+	async fn patch_add_array_index() {
+		let mut val = Value::parse("{ test: [1, 2, 3] }");
+		let ops = Value::parse(
+			"[{ op: 'add', path: '/test/1', value: 9 }, { op: 'add', path: '/test/4', value: 4 }]",
+		);
+		let res = Value::parse("{ test: [1, 9, 2, 3, 4] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_add_array_end() {
+		let mut val = Value::parse("{ test: [1, 2, 3] }");
+		let ops = Value::parse("[{ op: 'add', path: '/test/-', value: 4 }]");
+		let res = Value::parse("{ test: [1, 2, 3, 4] }");
+		val.patch(ops).unwrap();
+		assert_eq!(res, val);
+	}
+
+	#[tokio::test]
+	async fn patch_add_array_index_out_of_bounds() {
+		let mut val = Value::parse("{ test: [1, 2, 3] }");
+		let ops = Value::parse("[{ op: 'add', path: '/test/4', value: 4 }]");
+		assert!(val.patch(ops).is_err());
+	}
+
 	#[tokio::test]
 	async fn patch_change_invalid() {
 		// See https://github.com/surrealdb/surrealdb/issues/2001
@@ -59,6 +59,7 @@ pub struct SerializeSelectStatement {
 	timeout: Option<Timeout>,
 	parallel: Option<bool>,
 	explain: Option<Explain>,
+	distinct: Option<bool>,
 }
 
 impl serde::ser::SerializeStruct for SerializeSelectStatement {
@@ -109,6 +110,9 @@ impl serde::ser::SerializeStruct for SerializeSelectStatement {
 			"explain" => {
 				self.explain = value.serialize(ser::explain::opt::Serializer.wrap())?;
 			}
+			"distinct" => {
+				self.distinct = Some(value.serialize(ser::primitive::bool::Serializer.wrap())?);
+			}
 			key => {
 				return Err(Error::custom(format!("unexpected field `SelectStatement::{key}`")));
 			}
@@ -117,11 +121,12 @@ impl serde::ser::SerializeStruct for SerializeSelectStatement {
 	}
 
 	fn end(self) -> Result<Self::Ok, Error> {
-		match (self.expr, self.what, self.parallel) {
-			(Some(expr), Some(what), Some(parallel)) => Ok(SelectStatement {
+		match (self.expr, self.what, self.parallel, self.distinct) {
+			(Some(expr), Some(what), Some(parallel), Some(distinct)) => Ok(SelectStatement {
 				expr,
 				what,
 				parallel,
+				distinct,
 				explain: self.explain,
 				cond: self.cond,
 				split: self.split,
@@ -248,4 +253,14 @@ mod tests {
 		let value: SelectStatement = stmt.serialize(Serializer.wrap()).unwrap();
 		assert_eq!(value, stmt);
 	}
+
+	#[test]
+	fn with_distinct() {
+		let stmt = SelectStatement {
+			distinct: true,
+			..Default::default()
+		};
+		let value: SelectStatement = stmt.serialize(Serializer.wrap()).unwrap();
+		assert_eq!(value, stmt);
+	}
 }
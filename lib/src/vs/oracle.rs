@@ -49,6 +49,16 @@ pub enum Oracle {
 	// EpochCounter is designed to be used instead of the SysTimeCounter when the runtime environment
 	// does not provide a monotonic system clock, and the database is running in a single-node mode.
 	EpochCounter(EpochCounter),
+	// HybridLogicalClock versionstamp oracle is a HLC based on system time in milliseconds as the physical time
+	// and a 16-bit counter as the logical time.
+	//
+	// Unlike the SysTimeCounter, the physical time never goes backwards. When the system clock
+	// stalls or goes backwards, the last physical time is reused and the logical time is incremented,
+	// carrying over into the physical time when the counter overflows.
+	// Versionstamps read from the change feed, which may have been issued by other nodes, are
+	// merged with observe(), so that every versionstamp issued afterwards is ordered after them.
+	// Versionstamps which are issued concurrently on nodes with skewed clocks are not ordered.
+	HybridLogicalClock(HybridLogicalClock),
 }
 
 impl Oracle {
@@ -57,6 +67,7 @@ impl Oracle {
 		match self {
 			Oracle::SysTimeCounter(sys) => sys.now(),
 			Oracle::EpochCounter(epoch) => epoch.now(),
+			Oracle::HybridLogicalClock(hlc) => hlc.now(),
 		}
 	}
 }
@@ -119,6 +130,59 @@ impl EpochCounter {
 	}
 }
 
+pub struct HybridLogicalClock {
+	// The first element is the physical time in milliseconds of the last versionstamp.
+	// The second element is the logical time of the last versionstamp.
+	state: Mutex<(u64, u16)>,
+}
+
+impl HybridLogicalClock {
+	pub fn new() -> Self {
+		HybridLogicalClock {
+			state: Mutex::new((0, 0)),
+		}
+	}
+
+	// Returns a versionstamp which is strictly greater than any versionstamp
+	// previously issued or observed by this clock.
+	pub fn now(&self) -> Versionstamp {
+		let mut state = match self.state.lock() {
+			Ok(state) => state,
+			Err(poisoned) => poisoned.into_inner(),
+		};
+		let current_physical_time = millis_since_unix_epoch();
+		*state = if current_physical_time > state.0 {
+			(current_physical_time, 0)
+		} else {
+			tick(*state)
+		};
+		u64_u16_to_versionstamp(state.0, state.1)
+	}
+
+	// Merges a versionstamp issued by another clock, so that every versionstamp
+	// issued by this clock afterwards is greater than the observed one.
+	pub fn observe(&self, vs: Versionstamp) {
+		let mut state = match self.state.lock() {
+			Ok(state) => state,
+			Err(poisoned) => poisoned.into_inner(),
+		};
+		let mut physical = [0; 8];
+		physical.copy_from_slice(&vs[0..8]);
+		let remote = (u64::from_be_bytes(physical), u16::from_be_bytes([vs[8], vs[9]]));
+		if remote > *state {
+			*state = remote;
+		}
+	}
+}
+
+// Advances the logical time, carrying over into the physical time on overflow.
+fn tick((physical, logical): (u64, u16)) -> (u64, u16) {
+	match logical.checked_add(1) {
+		Some(logical) => (physical, logical),
+		None => (physical + 1, 0),
+	}
+}
+
 #[allow(unused)]
 fn now() -> Versionstamp {
 	let secs = secs_since_unix_epoch();
@@ -133,6 +197,15 @@ fn secs_since_unix_epoch() -> u64 {
 	since_the_epoch.as_secs()
 }
 
+// Returns the number of milliseconds since the Unix Epoch (January 1st, 1970 at UTC).
+fn millis_since_unix_epoch() -> u64 {
+	match SystemTime::now().duration_since(UNIX_EPOCH) {
+		Ok(since_the_epoch) => since_the_epoch.as_millis() as u64,
+		// The clock is set before the epoch, so rely on the logical time
+		Err(_) => 0,
+	}
+}
+
 mod tests {
 	#[allow(unused)]
 	use super::*;
@@ -166,4 +239,36 @@ mod tests {
 		let c = to_u128_be(o2.now());
 		assert!(b < c, "b = {}, c = {}", b, c);
 	}
+
+	#[test]
+	fn hybrid_logical_clock() {
+		let mut o = Oracle::HybridLogicalClock(HybridLogicalClock::new());
+		let mut last = to_u128_be(o.now());
+		for _ in 0..1000 {
+			let next = to_u128_be(o.now());
+			assert!(last < next, "last = {}, next = {}", last, next);
+			last = next;
+		}
+	}
+
+	#[test]
+	fn hybrid_logical_clock_observe() {
+		let o = HybridLogicalClock::new();
+		// A versionstamp from a node whose clock is far ahead
+		let remote = u64_u16_to_versionstamp(u64::MAX / 2, 7);
+		o.observe(remote);
+		let a = o.now();
+		assert_eq!(a, u64_u16_to_versionstamp(u64::MAX / 2, 8));
+		// Observing an older versionstamp does not move the clock backwards
+		o.observe(u64_u16_to_versionstamp(1, 0));
+		let b = o.now();
+		assert!(to_u128_be(a) < to_u128_be(b));
+	}
+
+	#[test]
+	fn hybrid_logical_clock_overflow() {
+		let o = HybridLogicalClock::new();
+		o.observe(u64_u16_to_versionstamp(u64::MAX / 2, u16::MAX));
+		assert_eq!(o.now(), u64_u16_to_versionstamp(u64::MAX / 2 + 1, 0));
+	}
 }
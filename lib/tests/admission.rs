@@ -0,0 +1,110 @@
+use jsonwebtoken::{encode, EncodingKey, Header};
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::iam::token::Claims;
+use surrealdb::iam::verify;
+use surrealdb::kvs::Datastore;
+use surrealdb::kvs::Priority;
+
+#[tokio::test]
+async fn admission_rejects_overloaded_writes() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test;
+		SELECT * FROM person;
+		BEGIN;
+		CREATE person:test;
+		CREATE person:other;
+		COMMIT;
+	";
+	let dbs = Datastore::new("memory").await?.with_transaction_limit(Some(1));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	// Hold the only write transaction open
+	let txn = dbs.transaction(true, false).await?;
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.kind() == "Overloaded" && e.to_string() == "The datastore is overloaded with write transactions. Try again later"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::TxOverloaded)));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::QueryNotExecuted)));
+	// Writes are accepted once the transaction has finished
+	drop(txn);
+	let res = &mut dbs.execute("CREATE person:test", &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn admission_sheds_low_priority_writes_first() -> Result<(), Error> {
+	let sql = "CREATE person;";
+	let dbs = Datastore::new("memory").await?.with_transaction_limit(Some(2));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	// Use half of the write transactions
+	let txn = dbs.transaction(true, false).await?;
+	//
+	let ses = ses.with_priority(Priority::Low);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::TxOverloaded)));
+	// Low priority writes are accepted once the load has dropped
+	drop(txn);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn admission_priority_from_token() -> Result<(), Error> {
+	let sql = "DEFINE TOKEN app ON DATABASE TYPE HS512 VALUE 'secret'";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert!(res.remove(0).result.is_ok());
+	// Sign a database token with the specified priority
+	let token = |pr: Option<&str>| {
+		let val = Claims {
+			exp: Some(i64::MAX),
+			ns: Some("test".to_owned()),
+			db: Some("test".to_owned()),
+			tk: Some("app".to_owned()),
+			pr: pr.map(str::to_owned),
+			..Claims::default()
+		};
+		let key = EncodingKey::from_secret(b"secret");
+		encode(&Header::new(jsonwebtoken::Algorithm::HS512), &val, &key).unwrap()
+	};
+	//
+	let mut ses = Session::default();
+	verify::token(&dbs, &mut ses, token(None)).await?;
+	assert_eq!(ses.pr, Priority::Normal);
+	//
+	let mut ses = Session::default();
+	verify::token(&dbs, &mut ses, token(Some("batch"))).await?;
+	assert_eq!(ses.pr, Priority::Low);
+	// The highest priority can not be claimed by a token
+	let mut ses = Session::default();
+	verify::token(&dbs, &mut ses, token(Some("system"))).await?;
+	assert_eq!(ses.pr, Priority::Normal);
+	// An unknown priority does not invalidate the token
+	let mut ses = Session::default();
+	verify::token(&dbs, &mut ses, token(Some("urgent"))).await?;
+	assert_eq!(ses.pr, Priority::Normal);
+	//
+	Ok(())
+}
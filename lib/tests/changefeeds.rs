@@ -0,0 +1,96 @@
+mod parse;
+use parse::Parse;
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::kvs::Datastore;
+use surrealdb::sql::Value;
+
+#[tokio::test]
+async fn table_change_feeds() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE person CHANGEFEED 1h;
+		CREATE person:test SET age = 20;
+		UPDATE person:test SET age = 21;
+		DELETE person:test;
+		CREATE other:test SET age = 20;
+		SHOW CHANGES FOR TABLE person;
+		SHOW CHANGES FOR TABLE other;
+		SHOW CHANGES FOR TABLE person LIMIT 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 8);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			[{ update: { id: person:test, age: 20 } }],
+			[{ patch: { id: person:test, diff: [{ op: 'replace', path: '/age', value: 21 }] } }],
+			[{ delete: { id: person:test } }],
+		]",
+	);
+	assert_eq!(tmp.pick(&["changes".into()]), val);
+	// The versionstamps of the transactions are increasing
+	let vs = match tmp.pick(&["versionstamp".into()]) {
+		Value::Array(v) => v,
+		v => panic!("unexpected versionstamps: {v}"),
+	};
+	assert!(vs.windows(2).all(|w| w[0] < w[1]));
+	// Changes can be read from a versionstamp onwards
+	let sql = format!("SHOW CHANGES FOR TABLE person SINCE {}", vs[1]);
+	let tmp = dbs.execute(&sql, &ses, None).await?.remove(0).result?;
+	let val = Value::parse(
+		"[
+			[{ patch: { id: person:test, diff: [{ op: 'replace', path: '/age', value: 21 }] } }],
+			[{ delete: { id: person:test } }],
+		]",
+	);
+	assert_eq!(tmp.pick(&["changes".into()]), val);
+	// Tables without a change feed are not recorded
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[[{ update: { id: person:test, age: 20 } }]]");
+	assert_eq!(tmp.pick(&["changes".into()]), val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn database_change_feeds() -> Result<(), Error> {
+	let sql = "
+		DEFINE DATABASE test CHANGEFEED 1h;
+		BEGIN;
+		CREATE person:test SET age = 20;
+		CREATE other:test SET age = 20;
+		COMMIT;
+		SHOW CHANGES FOR DATABASE;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// Changes in one transaction share a versionstamp
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			[{ update: { id: other:test, age: 20 } }, { update: { id: person:test, age: 20 } }],
+		]",
+	);
+	assert_eq!(tmp.pick(&["changes".into()]), val);
+	//
+	Ok(())
+}
@@ -45,7 +45,11 @@ async fn define_statement_database() -> Result<(), Error> {
 	let tmp = res.remove(0).result;
 	assert!(tmp.is_ok());
 	//
-	let tmp = res.remove(0).result?;
+	let mut tmp = res.remove(0).result?;
+	// The resource usage depends on timing, and is checked in usage.rs
+	if let Value::Object(v) = &mut tmp {
+		assert!(v.remove("usage").is_some());
+	}
 	let val = Value::parse(
 		"{
 			databases: { test: 'DEFINE DATABASE test' },
@@ -82,8 +86,10 @@ async fn define_statement_function() -> Result<(), Error> {
 			tokens: {},
 			functions: { test: 'DEFINE FUNCTION fn::test($first: string, $last: string) { RETURN $first + $last; }' },
 			params: {},
+			sequences: {},
 			scopes: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: {},
 		}",
@@ -93,6 +99,98 @@ async fn define_statement_function() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn define_statement_sequence() -> Result<(), Error> {
+	let sql = "
+		DEFINE SEQUENCE person START 100;
+		RETURN sequence::next('person');
+		RETURN sequence::next('person');
+		CREATE type::thing('person', sequence::next('person'));
+		RETURN sequence::next('other');
+		INFO FOR DB;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(100);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(101);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: person:102 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The sequence 'other' does not exist"
+	));
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"{
+			analyzers: {},
+			logins: {},
+			tokens: {},
+			functions: {},
+			params: {},
+			sequences: { person: 'DEFINE SEQUENCE person START 100' },
+			scopes: {},
+			tables: { person: 'DEFINE TABLE person SCHEMALESS' },
+		}",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn define_statement_sequence_in_transaction() -> Result<(), Error> {
+	let sql = "
+		BEGIN;
+		DEFINE SEQUENCE person START 100;
+		RETURN sequence::next('person');
+		COMMIT;
+		RETURN sequence::next('person');
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(100);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(101);
+	assert_eq!(tmp, val);
+	//
+	let sql = "RETURN sequence::next('person')";
+	let ses = Session::for_kv().with_ns("test").with_db("test").with_dry_run(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::from(102);
+	assert_eq!(tmp, val);
+	// A dry run does not advance the sequence
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::from(102);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn define_statement_table_drop() -> Result<(), Error> {
 	let sql = "
@@ -115,6 +213,7 @@ async fn define_statement_table_drop() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: { test: 'DEFINE TABLE test DROP SCHEMALESS' },
 		}",
@@ -146,6 +245,7 @@ async fn define_statement_table_schemaless() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: { test: 'DEFINE TABLE test SCHEMALESS' },
 		}",
@@ -181,6 +281,7 @@ async fn define_statement_table_schemafull() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: { test: 'DEFINE TABLE test SCHEMAFULL' },
 		}",
@@ -212,6 +313,7 @@ async fn define_statement_table_schemaful() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: { test: 'DEFINE TABLE test SCHEMAFULL' },
 		}",
@@ -1055,6 +1157,7 @@ async fn define_statement_analyzer() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: {}
 		}",
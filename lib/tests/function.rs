@@ -1,9 +1,11 @@
 mod parse;
 use parse::Parse;
+use surrealdb::dbs::CustomFunction;
+use surrealdb::dbs::QueryCapabilities;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::kvs::Datastore;
-use surrealdb::sql::{Number, Value};
+use surrealdb::sql::{Kind, Number, Value};
 
 async fn test_queries(sql: &str, desired_responses: &[&str]) -> Result<(), Error> {
 	let db = Datastore::new("memory").await?;
@@ -1193,6 +1195,63 @@ async fn function_count() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_count_estimate() -> Result<(), Error> {
+	let sql = r#"
+		DEFINE INDEX age ON person FIELDS age;
+		CREATE person:one SET age = 30;
+		CREATE person:two SET age = 30;
+		CREATE person:three SET age = 20;
+		UPDATE person:three SET age = 20;
+		DELETE person:one;
+		RETURN count::estimate('person');
+		ANALYZE TABLE person;
+		RETURN count::estimate('person');
+		RETURN count::estimate(type::table('person'));
+		CREATE person:four SET age = 40;
+		RETURN count::estimate('person');
+		ANALYZE INDEX age ON person;
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 13);
+	//
+	for _ in 0..6 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	// The table has not been analyzed yet
+	let tmp = res.remove(0).result?;
+	let val = Value::None;
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ cardinality: 2 }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	// The estimate is as of the last ANALYZE TABLE
+	let tmp = res.remove(0).result?;
+	let val = Value::from(2);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ cardinality: 3 }");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 // --------------------------------------------------
 // crypto
 // --------------------------------------------------
@@ -1893,6 +1952,24 @@ async fn function_parse_geo_hash_decode() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn function_parse_geo_hash_neighbours() -> Result<(), Error> {
+	let sql = r#"
+		RETURN geo::hash::neighbours('gbsuv');
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result?;
+	let val =
+		Value::parse("['gbsvj', 'gbsvn', 'gbsuy', 'gbsuw', 'gbsut', 'gbsus', 'gbsuu', 'gbsvh']");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 // --------------------------------------------------
 // is
 // --------------------------------------------------
@@ -2921,6 +2998,59 @@ async fn function_not() -> Result<(), Error> {
 	Ok(())
 }
 
+// --------------------------------------------------
+// patch
+// --------------------------------------------------
+
+#[tokio::test]
+async fn function_patch() -> Result<(), Error> {
+	let sql = r#"
+		RETURN patch({ a: 1, b: { c: 2 } }, [{ op: 'replace', path: '/b/c', value: 3 }, { op: 'remove', path: '/a' }]);
+		RETURN patch({ tags: ['one'] }, [{ op: 'add', path: '/tags', value: 'two' }]);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ b: { c: 3 } }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ tags: ['one', 'two'] }");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_patch_merge() -> Result<(), Error> {
+	let sql = r#"
+		RETURN patch::merge({ a: 'b', c: { d: 'e', f: 'g' } }, { a: 'z', c: { f: null } });
+		RETURN patch::merge({ a: 'b' }, { b: { c: null } });
+		RETURN patch::merge({ a: 'b' }, ['c']);
+	"#;
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 3);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ a: 'z', c: { d: 'e' } }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("{ a: 'b', b: {} }");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("['c']");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 // --------------------------------------------------
 // parse
 // --------------------------------------------------
@@ -4760,3 +4890,83 @@ async fn function_vector_similarity_cosine() -> Result<(), Error> {
 	assert!(tmp.is_err());
 	Ok(())
 }
+
+// --------------------------------------------------
+// custom
+// --------------------------------------------------
+
+#[tokio::test]
+async fn function_custom_registered() -> Result<(), Error> {
+	let sql = r#"
+		RETURN fn::greet("Tobie");
+		RETURN fn::greet(123);
+		RETURN fn::greet();
+		RETURN fn::fail("Tobie");
+		RETURN fn::panic("Tobie");
+	"#;
+	let greet = CustomFunction::new(vec![Kind::String], |args| {
+		Ok(Value::from(format!("Hello {}", args[0].clone().as_string())))
+	});
+	let fail = CustomFunction::new(vec![Kind::String], |_| Err(String::from("Lookup failed.")));
+	let panic = CustomFunction::new(vec![Kind::String], |_| panic!("unexpected"));
+	let dbs = Datastore::new("memory")
+		.await?
+		.with_function("greet", greet)
+		.with_function("fail", fail)
+		.with_function("panic", panic);
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(&sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from("Hello Tobie");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_err());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "Incorrect arguments for function fn::greet(). The function expects 1 argument."
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "There was a problem running the fn::fail() function. Lookup failed."
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "There was a problem running the fn::panic() function. The function panicked."
+	));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn function_custom_registered_disabled() -> Result<(), Error> {
+	let sql = r#"
+		RETURN fn::greet("Tobie");
+	"#;
+	let greet = CustomFunction::new(vec![Kind::String], |args| {
+		Ok(Value::from(format!("Hello {}", args[0].clone().as_string())))
+	});
+	let dbs = Datastore::new("memory")
+		.await?
+		.with_function("greet", greet)
+		.with_query_capabilities(QueryCapabilities::default().with_custom_functions(false));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "There was a problem running the fn::greet() function. Registered functions are not enabled."
+	));
+	//
+	Ok(())
+}
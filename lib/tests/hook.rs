@@ -0,0 +1,66 @@
+use std::sync::Arc;
+use std::sync::Mutex;
+use surrealdb::dbs::Hook;
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::kvs::Datastore;
+use surrealdb::sql::Thing;
+use surrealdb::sql::Value;
+
+#[derive(Default)]
+struct Recorder {
+	writes: Mutex<Vec<String>>,
+}
+
+impl Hook for Recorder {
+	fn on_create(&self, rid: &Thing, _after: &Value) -> Result<(), String> {
+		self.writes.lock().unwrap().push(format!("CREATE {rid}"));
+		Ok(())
+	}
+	fn on_update(&self, rid: &Thing, before: &Value, after: &Value) -> Result<(), String> {
+		if after.pick(&["age".into()]) < before.pick(&["age".into()]) {
+			return Err(format!("the age of {rid} can not decrease"));
+		}
+		self.writes.lock().unwrap().push(format!("UPDATE {rid}"));
+		Ok(())
+	}
+	fn on_delete(&self, rid: &Thing, _before: &Value) -> Result<(), String> {
+		self.writes.lock().unwrap().push(format!("DELETE {rid}"));
+		Ok(())
+	}
+}
+
+#[tokio::test]
+async fn hook_record_writes() -> Result<(), Error> {
+	let sql = "
+		CREATE person:test SET age = 20;
+		UPDATE person:test SET age = 21;
+		UPDATE person:test SET age = 18;
+		DELETE person:test;
+	";
+	let hook = Arc::new(Recorder::default());
+	let dbs = Datastore::new("memory").await?.with_hook(hook.clone());
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "The write was rejected by a datastore hook: the age of person:test can not decrease"
+	));
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let writes = hook.writes.lock().unwrap().clone();
+	assert_eq!(writes, vec!["CREATE person:test", "UPDATE person:test", "DELETE person:test"]);
+	//
+	Ok(())
+}
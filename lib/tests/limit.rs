@@ -93,3 +93,49 @@ async fn select_limit_fetch() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_limit_warnings() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie;
+		CREATE person:jaime;
+		SELECT * FROM person LIMIT 2;
+		SELECT * FROM person LIMIT 1;
+		SELECT * FROM person START 1 LIMIT 1;
+		SELECT * FROM person ORDER BY id LIMIT 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test").with_warnings(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert_eq!(
+		tmp.warnings,
+		vec!["More records matched than the LIMIT of 1, so the results were truncated"]
+	);
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert_eq!(
+		tmp.warnings,
+		vec!["More records matched than the LIMIT of 1, so the results were truncated"]
+	);
+	//
+	Ok(())
+}
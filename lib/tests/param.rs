@@ -30,6 +30,7 @@ async fn define_global_param() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: { test: 'DEFINE PARAM $test VALUE 12345' },
+			sequences: {},
 			scopes: {},
 			tables: {},
 		}",
@@ -1,6 +1,7 @@
 mod parse;
 
 use parse::Parse;
+use surrealdb::dbs::QueryCapabilities;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::key::bc::Bc;
@@ -44,6 +45,7 @@ async fn remove_statement_table() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: {}
 		}",
@@ -78,6 +80,7 @@ async fn remove_statement_analyzer() -> Result<(), Error> {
 			tokens: {},
 			functions: {},
 			params: {},
+			sequences: {},
 			scopes: {},
 			tables: {}
 		}",
@@ -100,6 +103,43 @@ macro_rules! check_none_val {
 	}};
 }
 
+#[tokio::test]
+async fn remove_statement_sequence() -> Result<(), Error> {
+	let sql = "
+		DEFINE SEQUENCE test;
+		RETURN sequence::next('test');
+		REMOVE SEQUENCE test;
+		RETURN sequence::next('test');
+		DEFINE SEQUENCE test;
+		RETURN sequence::next('test');
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(1);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_err());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::from(1);
+	assert_eq!(tmp, val);
+	Ok(())
+}
+
 #[tokio::test]
 async fn remove_statement_index() -> Result<(), Error> {
 	let sql = "
@@ -150,3 +190,25 @@ async fn remove_statement_index() -> Result<(), Error> {
 	}
 	Ok(())
 }
+
+#[tokio::test]
+async fn remove_statement_disabled() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE test SCHEMALESS;
+		REMOVE TABLE test;
+	";
+	let dbs = Datastore::new("memory")
+		.await?
+		.with_query_capabilities(QueryCapabilities::default().with_remove(false));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 2);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::RemoveDisabled)));
+	//
+	Ok(())
+}
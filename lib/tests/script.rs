@@ -2,6 +2,7 @@
 
 mod parse;
 use parse::Parse;
+use surrealdb::dbs::QueryCapabilities;
 use surrealdb::dbs::Session;
 use surrealdb::err::Error;
 use surrealdb::kvs::Datastore;
@@ -192,3 +193,26 @@ async fn script_function_module_os() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn script_function_disabled() -> Result<(), Error> {
+	let sql = "
+		RETURN function() {
+			return 'done';
+		};
+	";
+	let dbs = Datastore::new("memory")
+		.await?
+		.with_query_capabilities(QueryCapabilities::default().with_scripting(false));
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 1);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(
+		tmp.err(),
+		Some(e) if e.to_string() == "Problem with embedded script function. Embedded functions are not enabled."
+	));
+	//
+	Ok(())
+}
@@ -65,6 +65,60 @@ async fn select_field_value() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn select_distinct_field_value() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie', city = 'London';
+		CREATE person:jaime SET name = 'Jaime', city = 'London';
+		CREATE person:john SET name = 'John', city = 'Paris';
+		SELECT DISTINCT city FROM person;
+		SELECT DISTINCT VALUE city FROM person;
+		SELECT DISTINCT city FROM person ORDER BY city DESC LIMIT 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	let _ = res.remove(0).result?;
+	let _ = res.remove(0).result?;
+	let _ = res.remove(0).result?;
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				city: 'London'
+			},
+			{
+				city: 'Paris'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			'London',
+			'Paris',
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				city: 'Paris'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn select_expression_value() -> Result<(), Error> {
 	let sql = "
@@ -143,6 +197,43 @@ async fn select_expression_value() -> Result<(), Error> {
 	Ok(())
 }
 
+#[tokio::test]
+async fn select_array_wildcards_and_predicates() -> Result<(), Error> {
+	let sql = "
+		CREATE product:test CONTENT {
+			items: [
+				{ name: 'one', price: 5 },
+				{ name: 'two', price: 15 },
+				{ name: 'three', price: 25 },
+			]
+		};
+		SELECT items[*].price AS prices FROM product:test;
+		SELECT items[price > 10].name AS names FROM product:test;
+		UPDATE product:test SET items[price > 10].sale = true RETURN items[WHERE sale = true].name AS names;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ prices: [5, 15, 25] }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ names: ['two', 'three'] }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ names: ['two', 'three'] }]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
 #[tokio::test]
 async fn select_dynamic_array_keys_and_object_keys() -> Result<(), Error> {
 	let sql = "
@@ -683,3 +774,203 @@ async fn select_where_explain() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn select_where_warnings() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie';
+		DEFINE INDEX person_name ON TABLE person COLUMNS name;
+		SELECT * FROM person WHERE name = 'Tobie';
+		SELECT * FROM person WHERE age > 30;
+		SELECT * FROM person;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test").with_warnings(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert_eq!(
+		tmp.warnings,
+		vec!["The query on table 'person' does not use an index, so the whole table is scanned"]
+	);
+	//
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	// Warnings are only reported when enabled
+	let sql = "SELECT * FROM person WHERE age > 30";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0);
+	assert!(tmp.result.is_ok());
+	assert!(tmp.warnings.is_empty());
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_where_suggest_index() -> Result<(), Error> {
+	let sql = "
+		CREATE person:tobie SET name = 'Tobie', email = 'tobie@surrealdb.com', age = 30;
+		CREATE person:jaime SET name = 'Jaime', email = 'jaime@surrealdb.com', age = 20;
+		SELECT name FROM person WHERE email = 'tobie@surrealdb.com';
+		SELECT name FROM person WHERE email = 'jaime@surrealdb.com' AND age > 10;
+		SELECT name FROM person WHERE age > 10;
+		SELECT name FROM person WHERE name = 'Tobie' OR email = 'jaime@surrealdb.com';
+		SUGGEST INDEX;
+		DEFINE INDEX person_email ON person FIELDS email;
+		SUGGEST INDEX;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..6 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{
+				table: 'person',
+				field: 'email',
+				scans: 2,
+				statement: 'DEFINE INDEX person_email ON person FIELDS email'
+			}
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_order_by_ties_use_id() -> Result<(), Error> {
+	let sql = "
+		CREATE person:c SET age = 30;
+		CREATE person:a SET age = 30;
+		CREATE person:b SET age = 20;
+		SELECT * FROM [person:c, person:a, person:b] ORDER BY age;
+		SELECT * FROM [person:c, person:a, person:b] ORDER BY age DESC LIMIT 2;
+		SELECT age FROM [person:c, person:a, person:b] ORDER BY age DESC START 1 LIMIT 1;
+		SELECT VALUE id FROM [person:c, person:a, person:b] ORDER BY age DESC START 1 LIMIT 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 7);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ age: 20, id: person:b },
+			{ age: 30, id: person:a },
+			{ age: 30, id: person:c },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ age: 30, id: person:a },
+			{ age: 30, id: person:c },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse(
+		"[
+			{ age: 30 },
+		]",
+	);
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:c]");
+	assert_eq!(tmp, val);
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn select_start_after_record() -> Result<(), Error> {
+	let sql = "
+		CREATE person:a SET age = 30;
+		CREATE person:b SET age = 20;
+		CREATE person:c SET age = 30;
+		SELECT age FROM person ORDER BY age START AFTER [30, person:a] LIMIT 1;
+		SELECT VALUE id FROM person START AFTER person:a LIMIT 1;
+		DELETE person:a;
+		SELECT VALUE id FROM person START AFTER person:a LIMIT 1;
+		SELECT age FROM person ORDER BY age START AFTER [20, person:a] LIMIT 1;
+		SELECT VALUE id FROM person ORDER BY age START AFTER person:a LIMIT 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 9);
+	//
+	for _ in 0..3 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ age: 30 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:b]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(tmp.is_ok());
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[person:b]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ age: 20 }]");
+	assert_eq!(tmp, val);
+	//
+	let tmp = res.remove(0).result;
+	assert!(matches!(tmp, Err(Error::InvalidCursor { .. })));
+	//
+	Ok(())
+}
@@ -104,3 +104,43 @@ async fn define_foreign_table() -> Result<(), Error> {
 	//
 	Ok(())
 }
+
+#[tokio::test]
+async fn define_table_with_expiry() -> Result<(), Error> {
+	let sql = "
+		DEFINE TABLE session SCHEMALESS EXPIRE 1s;
+		DEFINE INDEX token ON TABLE session COLUMNS token UNIQUE;
+		CREATE session:one SET token = 'one';
+		CREATE session:two SET token = 'two';
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	for _ in 0..4 {
+		assert!(res.remove(0).result.is_ok());
+	}
+	// Records are kept until they have expired
+	dbs.expire().await?;
+	let res = &mut dbs.execute("SELECT id FROM session", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: session:one }, { id: session:two }]");
+	assert_eq!(tmp, val);
+	// Writing a record again resets its expiry. The sleeps only ever add
+	// to the elapsed time, so session:one has always expired, and there
+	// are 900ms to spare before session:two expires.
+	tokio::time::sleep(std::time::Duration::from_millis(1000)).await;
+	let res = &mut dbs.execute("UPDATE session:two SET seen = true", &ses, None).await?;
+	assert!(res.remove(0).result.is_ok());
+	tokio::time::sleep(std::time::Duration::from_millis(100)).await;
+	dbs.expire().await?;
+	let res = &mut dbs.execute("SELECT id FROM session", &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	let val = Value::parse("[{ id: session:two }]");
+	assert_eq!(tmp, val);
+	// The index entries of an expired record are removed too
+	let res = &mut dbs.execute("CREATE session:three SET token = 'one'", &ses, None).await?;
+	assert!(res.remove(0).result.is_ok());
+	//
+	Ok(())
+}
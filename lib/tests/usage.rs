@@ -0,0 +1,130 @@
+use surrealdb::dbs::Session;
+use surrealdb::err::Error;
+use surrealdb::kvs::Datastore;
+use surrealdb::sql::Value;
+
+#[tokio::test]
+async fn usage_for_namespace() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one SET name = 'Tobie';
+		CREATE person:two SET name = 'Jaime';
+		CREATE person:three SET name = 'Lizzie';
+		SELECT * FROM person;
+		DELETE person:one;
+		INFO FOR NS;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 6);
+	//
+	for _ in 0..5 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).result?;
+	let tmp = tmp.pick(&["usage".into()]);
+	assert!(matches!(tmp.pick(&["time".into()]), Value::Duration(_)));
+	assert_eq!(tmp.pick(&["reads".into()]), Value::from(7));
+	assert_eq!(tmp.pick(&["writes".into()]), Value::from(4));
+	assert!(tmp.pick(&["bytes".into()]) > Value::from(0));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn usage_separate_namespaces() -> Result<(), Error> {
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("one").with_db("test");
+	let res = &mut dbs.execute("CREATE person:tobie", &ses, None).await?;
+	assert!(res.remove(0).result.is_ok());
+	let ses = Session::for_kv().with_ns("two").with_db("test");
+	let res = &mut dbs.execute("SELECT * FROM person", &ses, None).await?;
+	assert!(res.remove(0).result.is_ok());
+	// Statements without a namespace are not accounted
+	let ses = Session::for_kv();
+	let res = &mut dbs.execute("INFO FOR KV", &ses, None).await?;
+	assert!(res.remove(0).result.is_ok());
+	//
+	let usage = dbs.usage();
+	assert_eq!(usage.len(), 2);
+	assert_eq!(usage[0].0, "one");
+	assert_eq!(usage[0].1.reads, 1);
+	assert_eq!(usage[0].1.writes, 1);
+	assert_eq!(usage[1].0, "two");
+	assert_eq!(usage[1].1.reads, 0);
+	assert_eq!(usage[1].1.writes, 0);
+	//
+	let sql = "INFO FOR NS";
+	let ses = Session::for_kv().with_ns("two").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	let tmp = res.remove(0).result?;
+	assert_eq!(tmp.pick(&["usage".into(), "writes".into()]), Value::from(0));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn usage_for_statement() -> Result<(), Error> {
+	let sql = "
+		CREATE person:one SET name = 'Tobie';
+		CREATE person:two SET name = 'Jaime';
+		SELECT * FROM person;
+		DELETE person;
+		RETURN 1;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test").with_stats(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 5);
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert_eq!((tmp.reads, tmp.writes), (1, 1));
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert_eq!((tmp.reads, tmp.writes), (1, 1));
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert_eq!((tmp.reads, tmp.writes), (2, 0));
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert_eq!((tmp.reads, tmp.writes), (2, 2));
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert_eq!((tmp.reads, tmp.writes), (0, 0));
+	//
+	Ok(())
+}
+
+#[tokio::test]
+async fn usage_for_statement_indexes() -> Result<(), Error> {
+	let sql = "
+		DEFINE INDEX person_name ON TABLE person COLUMNS name;
+		CREATE person:one SET name = 'Tobie';
+		SELECT * FROM person WHERE name = 'Tobie';
+		SELECT * FROM person WHERE age > 30;
+	";
+	let dbs = Datastore::new("memory").await?;
+	let ses = Session::for_kv().with_ns("test").with_db("test").with_stats(true);
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert_eq!(res.len(), 4);
+	//
+	for _ in 0..2 {
+		let tmp = res.remove(0).result;
+		assert!(tmp.is_ok());
+	}
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert_eq!(tmp.indexes, vec!["person_name"]);
+	//
+	let tmp = res.remove(0).stats.unwrap();
+	assert!(tmp.indexes.is_empty());
+	// Statistics are only reported when enabled
+	let sql = "SELECT * FROM person";
+	let ses = Session::for_kv().with_ns("test").with_db("test");
+	let res = &mut dbs.execute(sql, &ses, None).await?;
+	assert!(res.remove(0).stats.is_none());
+	//
+	Ok(())
+}
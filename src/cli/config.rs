@@ -2,6 +2,7 @@
 use crate::net::client_ip::ClientIp;
 #[cfg(feature = "has-storage")]
 use once_cell::sync::OnceCell;
+use std::time::Duration;
 use std::{net::SocketAddr, path::PathBuf};
 
 #[cfg(feature = "has-storage")]
@@ -17,4 +18,5 @@ pub struct Config {
 	pub pass: Option<String>,
 	pub crt: Option<PathBuf>,
 	pub key: Option<PathBuf>,
+	pub idempotency_window: Duration,
 }
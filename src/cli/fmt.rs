@@ -0,0 +1,87 @@
+use crate::err::Error;
+use clap::Args;
+use glob::glob;
+use std::io::{Error as IoError, ErrorKind};
+use surrealdb::error::Db as DbError;
+use surrealdb::sql::format;
+
+#[derive(Args, Debug)]
+pub struct FmtCommandArguments {
+	#[arg(help = "Glob pattern for the files to format")]
+	#[arg(default_value = "**/*.surql")]
+	pattern: String,
+	#[arg(help = "Check that the files are formatted, without changing them")]
+	#[arg(long)]
+	check: bool,
+}
+
+pub async fn init(args: FmtCommandArguments) -> Result<(), Error> {
+	let FmtCommandArguments {
+		pattern,
+		check,
+	} = args;
+
+	let entries = match glob(&pattern) {
+		Ok(entries) => entries,
+		Err(error) => {
+			eprintln!("Error parsing glob pattern {pattern}: {error}");
+
+			return Err(Error::Io(IoError::new(
+				ErrorKind::Other,
+				format!("Error parsing glob pattern {pattern}: {error}"),
+			)));
+		}
+	};
+
+	let mut has_entries = false;
+	let mut unformatted = 0;
+
+	for entry in entries.flatten() {
+		let file_content = tokio::fs::read_to_string(entry.clone()).await?;
+		let formatted = match format(&file_content) {
+			Ok(formatted) => formatted,
+			// Files with comments are left unchanged, as the comments would be removed
+			Err(DbError::QueryComments) => {
+				println!("{}: skipped, as it contains comments", entry.display());
+				has_entries = true;
+				continue;
+			}
+			Err(error) => {
+				println!("{}: KO", entry.display());
+				eprintln!("{error}");
+
+				return Err(crate::err::Error::from(error));
+			}
+		};
+
+		if formatted != file_content {
+			if check {
+				println!("{}: not formatted", entry.display());
+				unformatted += 1;
+			} else {
+				tokio::fs::write(entry.clone(), formatted).await?;
+				println!("{}: formatted", entry.display());
+			}
+		}
+
+		has_entries = true;
+	}
+
+	if !has_entries {
+		eprintln!("No files found for pattern {pattern}");
+
+		return Err(Error::Io(IoError::new(
+			ErrorKind::NotFound,
+			format!("No files found for pattern {pattern}"),
+		)));
+	}
+
+	if unformatted > 0 {
+		return Err(Error::Io(IoError::new(
+			ErrorKind::Other,
+			format!("{unformatted} files are not formatted"),
+		)));
+	}
+
+	Ok(())
+}
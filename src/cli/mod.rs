@@ -2,6 +2,7 @@ pub(crate) mod abstraction;
 mod backup;
 mod config;
 mod export;
+mod fmt;
 mod import;
 mod isready;
 mod sql;
@@ -15,9 +16,12 @@ mod version;
 use crate::cnf::LOGO;
 use backup::BackupCommandArguments;
 use clap::{Parser, Subcommand};
+#[cfg(all(test, feature = "has-storage"))]
+pub(crate) use config::Config;
 #[cfg(feature = "has-storage")]
 pub use config::CF;
 use export::ExportCommandArguments;
+use fmt::FmtCommandArguments;
 use import::ImportCommandArguments;
 use isready::IsReadyCommandArguments;
 use sql::SqlCommandArguments;
@@ -75,6 +79,8 @@ enum Commands {
 	IsReady(IsReadyCommandArguments),
 	#[command(about = "Validate SurrealQL query files")]
 	Validate(ValidateCommandArguments),
+	#[command(about = "Format SurrealQL query files")]
+	Fmt(FmtCommandArguments),
 }
 
 pub async fn init() -> ExitCode {
@@ -90,6 +96,7 @@ pub async fn init() -> ExitCode {
 		Commands::Sql(args) => sql::init(args).await,
 		Commands::IsReady(args) => isready::init(args).await,
 		Commands::Validate(args) => validate::init(args).await,
+		Commands::Fmt(args) => fmt::init(args).await,
 	};
 	if let Err(e) = output {
 		error!("{}", e);
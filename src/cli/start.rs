@@ -13,6 +13,7 @@ use clap::Args;
 use ipnet::IpNet;
 use std::net::SocketAddr;
 use std::path::PathBuf;
+use std::time::Duration;
 
 #[derive(Args, Debug)]
 pub struct StartCommandArguments {
@@ -40,6 +41,11 @@ pub struct StartCommandArguments {
 	#[arg(env = "SURREAL_BIND", short = 'b', long = "bind")]
 	#[arg(default_value = "0.0.0.0:8000")]
 	listen_addresses: Vec<SocketAddr>,
+	#[arg(help = "How long the responses to requests with an idempotency key are kept")]
+	#[arg(env = "SURREAL_IDEMPOTENCY_WINDOW", long)]
+	#[arg(default_value = "24h")]
+	#[arg(value_parser = super::validator::duration)]
+	idempotency_window: Duration,
 	#[command(flatten)]
 	dbs: StartCommandDbsOptions,
 	#[arg(help = "Encryption key to use for on-disk encryption")]
@@ -93,6 +99,7 @@ pub async fn init(
 		password: pass,
 		client_ip,
 		listen_addresses,
+		idempotency_window,
 		dbs,
 		web,
 		log: CustomEnvFilter(log),
@@ -117,6 +124,7 @@ pub async fn init(
 		pass,
 		crt: web.as_ref().and_then(|x| x.web_crt.clone()),
 		key: web.as_ref().and_then(|x| x.web_key.clone()),
+		idempotency_window,
 	});
 	// Initiate environment
 	env::init().await?;
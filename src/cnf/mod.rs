@@ -33,6 +33,10 @@ pub const MAX_CONCURRENT_CALLS: usize = 24;
 #[cfg(feature = "has-storage")]
 pub const WEBSOCKET_PING_FREQUENCY: Duration = Duration::from_secs(5);
 
+/// Specifies the frequency with which keys whose time-to-live has passed are removed
+#[cfg(feature = "has-storage")]
+pub const KEY_EXPIRY_INTERVAL: Duration = Duration::from_secs(10);
+
 /// The version identifier of this build
 pub static PKG_VERSION: Lazy<String> = Lazy::new(|| match option_env!("SURREAL_BUILD_METADATA") {
 	Some(metadata) if !metadata.trim().is_empty() => {
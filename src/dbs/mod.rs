@@ -1,8 +1,10 @@
 use crate::cli::CF;
+use crate::cnf::KEY_EXPIRY_INTERVAL;
 use crate::err::Error;
 use clap::Args;
 use once_cell::sync::OnceCell;
 use std::time::Duration;
+use surrealdb::dbs::QueryCapabilities;
 use surrealdb::kvs::Datastore;
 
 pub static DB: OnceCell<Datastore> = OnceCell::new();
@@ -21,6 +23,24 @@ pub struct StartCommandDbsOptions {
 	#[arg(env = "SURREAL_TRANSACTION_TIMEOUT", long)]
 	#[arg(value_parser = super::cli::validator::duration)]
 	transaction_timeout: Option<Duration>,
+	#[arg(help = "The maximum number of write transactions which can be open at once")]
+	#[arg(env = "SURREAL_TRANSACTION_LIMIT", long)]
+	transaction_limit: Option<usize>,
+	#[arg(help = "Whether to disable embedded scripting functions")]
+	#[arg(env = "SURREAL_DENY_SCRIPTING", long)]
+	#[arg(default_value_t = false)]
+	deny_scripting: bool,
+	#[arg(help = "Whether to disable remote HTTP request functions")]
+	#[arg(env = "SURREAL_DENY_HTTP", long)]
+	#[arg(default_value_t = false)]
+	deny_http: bool,
+	#[arg(help = "Whether to disable REMOVE statements")]
+	#[arg(env = "SURREAL_DENY_REMOVE", long)]
+	#[arg(default_value_t = false)]
+	deny_remove: bool,
+	#[arg(help = "The only hosts which remote HTTP request functions can connect to")]
+	#[arg(env = "SURREAL_ALLOW_HOSTS", long, value_delimiter = ',')]
+	allow_hosts: Vec<String>,
 }
 
 pub async fn init(
@@ -28,6 +48,11 @@ pub async fn init(
 		strict_mode,
 		query_timeout,
 		transaction_timeout,
+		transaction_limit,
+		deny_scripting,
+		deny_http,
+		deny_remove,
+		allow_hosts,
 	}: StartCommandDbsOptions,
 ) -> Result<(), Error> {
 	// Get local copy of options
@@ -42,16 +67,49 @@ pub async fn init(
 	if let Some(v) = transaction_timeout {
 		debug!("Maximum transaction processing timeout is {v:?}");
 	}
+	if let Some(v) = transaction_limit {
+		debug!("Maximum number of open write transactions is {v}");
+	}
+	// Setup the functionality which queries can use
+	let mut capabilities = QueryCapabilities::default()
+		.with_scripting(!deny_scripting)
+		.with_http(!deny_http)
+		.with_remove(!deny_remove);
+	if !allow_hosts.is_empty() {
+		debug!("Remote HTTP requests are only allowed to {}", allow_hosts.join(", "));
+		capabilities = capabilities.with_allowed_hosts(allow_hosts);
+	}
 	// Parse and setup the desired kv datastore
 	let dbs = Datastore::new(&opt.path)
 		.await?
 		.with_notifications()
 		.with_strict_mode(strict_mode)
 		.with_query_timeout(query_timeout)
-		.with_transaction_timeout(transaction_timeout);
+		.with_transaction_timeout(transaction_timeout)
+		.with_transaction_limit(transaction_limit)
+		.with_query_capabilities(capabilities);
 	dbs.bootstrap().await?;
 	// Store database instance
 	let _ = DB.set(dbs);
+	// Remove the expired keys
+	tokio::spawn(expiry());
 	// All ok
 	Ok(())
 }
+
+/// Periodically removes the keys whose time-to-live has passed
+async fn expiry() {
+	// Create the interval ticker
+	let mut interval = tokio::time::interval(KEY_EXPIRY_INTERVAL);
+	// Loop indefinitely
+	loop {
+		// Wait for the timer
+		interval.tick().await;
+		// Remove the expired keys
+		if let Some(dbs) = DB.get() {
+			if let Err(e) = dbs.expire().await {
+				warn!("Unable to remove the expired keys: {e}");
+			}
+		}
+	}
+}
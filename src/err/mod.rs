@@ -32,6 +32,15 @@ pub enum Error {
 	#[error("The operation is unsupported")]
 	OperationUnsupported,
 
+	#[error("A request with this idempotency key is already being processed")]
+	IdempotencyConflict,
+
+	#[error("The idempotency key was already used for a different request")]
+	IdempotencyMismatch,
+
+	#[error("There are too many requests with an idempotency key being processed")]
+	IdempotencyFull,
+
 	#[error("There was a problem with the database: {0}")]
 	Db(#[from] SurrealError),
 
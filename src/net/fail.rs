@@ -10,6 +10,8 @@ struct Message {
 	description: Option<String>,
 	#[serde(skip_serializing_if = "Option::is_none")]
 	information: Option<String>,
+	#[serde(skip_serializing_if = "Option::is_none")]
+	kind: Option<&'static str>,
 }
 
 pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rejection> {
@@ -21,6 +23,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 					details: Some("Authentication failed".to_string()),
 					description: Some("Your authentication details are invalid. Reauthenticate using valid authentication parameters.".to_string()),
 					information: Some(err.to_string()),
+					kind: None,
 				}),
 				StatusCode::FORBIDDEN,
 			)),
@@ -30,6 +33,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 					details: Some("Unsupported media type".to_string()),
 					description: Some("The request needs to adhere to certain constraints. Refer to the documentation for supported content types.".to_string()),
 					information: None,
+					kind: None,
 				}),
 				StatusCode::UNSUPPORTED_MEDIA_TYPE,
 			)),
@@ -39,15 +43,50 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 					details: Some("Health check failed".to_string()),
 					description: Some("The database health check for this instance failed. There was an issue with the underlying storage engine.".to_string()),
 					information: Some(err.to_string()),
+					kind: None,
 				}),
 				StatusCode::INTERNAL_SERVER_ERROR,
 			)),
+			Error::IdempotencyConflict => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 409,
+					details: Some("Request already in progress".to_string()),
+					description: Some("A request with the same idempotency key is still being processed. Retry the request once it has completed.".to_string()),
+					information: Some(err.to_string()),
+					kind: None,
+				}),
+				StatusCode::CONFLICT,
+			)),
+			Error::IdempotencyMismatch => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 422,
+					details: Some("Idempotency key reused".to_string()),
+					description: Some("The idempotency key was already used for a request with a different method, path, query, or body. Use a new idempotency key for each request.".to_string()),
+					information: Some(err.to_string()),
+					kind: None,
+				}),
+				StatusCode::UNPROCESSABLE_ENTITY,
+			)),
+			Error::IdempotencyFull => Ok(warp::reply::with_status(
+				warp::reply::json(&Message {
+					code: 503,
+					details: Some("Service unavailable".to_string()),
+					description: Some("There are too many requests with an idempotency key being processed. Retry the request later.".to_string()),
+					information: Some(err.to_string()),
+					kind: None,
+				}),
+				StatusCode::SERVICE_UNAVAILABLE,
+			)),
 			_ => Ok(warp::reply::with_status(
 				warp::reply::json(&Message {
 					code: 400,
 					details: Some("Request problems detected".to_string()),
 					description: Some("There is a problem with your request. Refer to the documentation for further information.".to_string()),
 					information: Some(err.to_string()),
+					kind: match err {
+						Error::Db(surrealdb::Error::Db(e)) => Some(e.kind()),
+						_ => None,
+					},
 				}),
 				StatusCode::BAD_REQUEST,
 			))
@@ -59,6 +98,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Requested resource not found".to_string()),
 				description: Some("The requested resource does not exist. Check that you have entered the url correctly.".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::NOT_FOUND,
 		))
@@ -69,6 +109,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Request problems detected".to_string()),
 				description: Some("The request appears to be missing a required header. Refer to the documentation for request requirements.".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::PRECONDITION_FAILED,
 		))
@@ -79,6 +120,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Payload too large".to_string()),
 				description: Some("The request has exceeded the maximum payload size. Refer to the documentation for the request limitations.".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::PAYLOAD_TOO_LARGE,
 		))
@@ -89,6 +131,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Not implemented".to_string()),
 				description: Some("The server either does not recognize the query, or it lacks the ability to fulfill the request.".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::NOT_IMPLEMENTED,
 		))
@@ -99,6 +142,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Not implemented".to_string()),
 				description: Some("The server either does not recognize a request header, or it lacks the ability to fulfill the request.".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::NOT_IMPLEMENTED,
 		))
@@ -109,6 +153,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Requested method not allowed".to_string()),
 				description: Some("The requested http method is not allowed for this resource. Refer to the documentation for allowed methods.".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::METHOD_NOT_ALLOWED,
 		))
@@ -119,6 +164,7 @@ pub async fn recover(err: warp::Rejection) -> Result<impl warp::Reply, warp::Rej
 				details: Some("Internal server error".to_string()),
 				description: Some("There was a problem with our servers, and we have been notified. Refer to the documentation for further information".to_string()),
 				information: None,
+				kind: None,
 			}),
 			StatusCode::INTERNAL_SERVER_ERROR,
 		))
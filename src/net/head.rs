@@ -1,5 +1,6 @@
 use crate::cnf::PKG_NAME;
 use crate::cnf::PKG_VERSION;
+use crate::net::idempotency;
 use surrealdb::cnf::SERVER_NAME;
 
 const ID: &str = "ID";
@@ -37,5 +38,6 @@ pub fn cors() -> warp::filters::cors::Builder {
 			NS.parse().unwrap(),
 			DB.parse().unwrap(),
 			ID.parse().unwrap(),
+			idempotency::HEADER.parse().unwrap(),
 		])
 }
@@ -0,0 +1,506 @@
+use crate::cli::CF;
+use crate::err::Error;
+use bytes::Bytes;
+use http::{HeaderMap, Method, StatusCode};
+use once_cell::sync::Lazy;
+use std::collections::hash_map::DefaultHasher;
+use std::collections::HashMap;
+use std::hash::{Hash, Hasher};
+use std::sync::{Mutex, MutexGuard};
+use std::time::{Duration, Instant};
+use warp::path::FullPath;
+use warp::reply::Response;
+use warp::{Filter, Reply};
+
+pub const HEADER: &str = "idempotency-key";
+
+/// The maximum number of idempotency keys which are tracked at once
+const MAX_ENTRIES: usize = 10_000;
+
+/// How often the expired responses are removed from the store
+const EXPIRY_INTERVAL: Duration = Duration::from_secs(60);
+
+/// The requests which have been processed for each idempotency key
+static STORE: Lazy<Mutex<Store>> = Lazy::new(Default::default);
+
+/// Get the request store, even if a previous holder of the lock
+/// panicked, as every change to the store is a single operation
+fn store() -> MutexGuard<'static, Store> {
+	STORE.lock().unwrap_or_else(|e| e.into_inner())
+}
+
+/// A response which was returned for an idempotency key
+#[derive(Clone, Debug)]
+struct Stored {
+	status: StatusCode,
+	headers: HeaderMap,
+	body: Bytes,
+}
+
+impl Stored {
+	fn response(&self) -> Response {
+		let mut res = Response::new(self.body.clone().into());
+		*res.status_mut() = self.status;
+		*res.headers_mut() = self.headers.clone();
+		res
+	}
+}
+
+/// A request which was made with an idempotency key
+struct Entry {
+	// When the request was first received
+	time: Instant,
+	// A hash of the method, path, query, and body of the request
+	hash: u64,
+	// A hash of the method, path, and query of the request
+	route: u64,
+	// The order in which the key was claimed
+	claim: u64,
+	// The response, once the request has been processed
+	response: Option<Stored>,
+}
+
+/// A request with an idempotency key, which may claim the key while it is processed
+#[derive(Clone, Debug)]
+struct Pending {
+	key: String,
+	// A hash of the method, path, and query of the request
+	route: u64,
+	// The number of keys which were claimed before the request was received
+	since: u64,
+}
+
+/// What should happen with a request with an idempotency key
+#[derive(Debug)]
+enum Claim {
+	// The request has not been seen before, so process it
+	Process,
+	// The request was already processed, so replay the response
+	Replay(Stored),
+}
+
+/// Rejects a request with the response which was previously stored
+#[derive(Debug)]
+struct Replay(Stored);
+
+impl warp::reject::Reject for Replay {}
+
+#[derive(Default)]
+struct Store {
+	entries: HashMap<String, Entry>,
+	// The number of keys which have been claimed
+	claims: u64,
+}
+
+impl Store {
+	/// Claim an idempotency key for a request with the specified hashes
+	fn claim(
+		&mut self,
+		key: &str,
+		hash: u64,
+		route: u64,
+		window: Duration,
+	) -> Result<Claim, Error> {
+		// Check if this key has been used recently
+		if let Some(v) = self.entries.get(key) {
+			if v.time.elapsed() < window {
+				// The key was used for a different request
+				if v.hash != hash {
+					return Err(Error::IdempotencyMismatch);
+				}
+				// The key is still being processed, or has completed
+				return match &v.response {
+					Some(res) => Ok(Claim::Replay(res.clone())),
+					None => Err(Error::IdempotencyConflict),
+				};
+			}
+		}
+		// Make room for this key if the store is full
+		if self.entries.len() >= MAX_ENTRIES {
+			self.expire(window);
+		}
+		if self.entries.len() >= MAX_ENTRIES {
+			// Evict the oldest completed request
+			let oldest = self
+				.entries
+				.iter()
+				.filter(|(_, v)| v.response.is_some())
+				.min_by_key(|(_, v)| v.time)
+				.map(|(k, _)| k.clone());
+			match oldest {
+				Some(k) => self.entries.remove(&k),
+				None => return Err(Error::IdempotencyFull),
+			};
+		}
+		// Mark the request as being processed
+		self.claims += 1;
+		self.entries.insert(
+			key.to_owned(),
+			Entry {
+				time: Instant::now(),
+				hash,
+				route,
+				claim: self.claims,
+				response: None,
+			},
+		);
+		Ok(Claim::Process)
+	}
+
+	/// Store the response of a request which has been processed
+	fn complete(&mut self, key: &str, response: Stored) {
+		if let Some(v) = self.entries.get_mut(key) {
+			if v.response.is_none() {
+				v.response = Some(response);
+			}
+		}
+	}
+
+	/// Release the claim of a request which failed, so that it can be retried.
+	/// The key is only released if it was claimed by the same route after the
+	/// request was received, and not by an earlier request which is still
+	/// being processed.
+	fn release(&mut self, req: &Pending) {
+		if matches!(self.entries.get(&req.key), Some(v) if v.response.is_none() && v.route == req.route && v.claim > req.since)
+		{
+			self.entries.remove(&req.key);
+		}
+	}
+
+	/// Remove all of the requests which are older than the window
+	fn expire(&mut self, window: Duration) {
+		self.entries.retain(|_, v| v.time.elapsed() < window);
+	}
+}
+
+/// Stores the successful responses of all mutating requests with an
+/// idempotency key, and releases the key of any request which failed.
+/// The wrapped routes must read the request body with [`body`], or
+/// call [`claim`] if the request has no body.
+pub fn wrap<F, R>(routes: F) -> impl Filter<Extract = (Response,), Error = warp::Rejection> + Clone
+where
+	F: Filter<Extract = (R,), Error = warp::Rejection> + Clone + Send + Sync + 'static,
+	R: Reply,
+{
+	pending()
+		.and(routes.map(|res: R| Ok::<Response, warp::Rejection>(res.into_response())).or_else(
+			|err: warp::Rejection| async move {
+				Ok::<_, warp::Rejection>((Err::<Response, warp::Rejection>(err),))
+			},
+		))
+		.and_then(record)
+}
+
+/// Reads the request body, and replays the stored response if a
+/// request with the same idempotency key has already been processed.
+pub fn body() -> impl Filter<Extract = (Bytes,), Error = warp::Rejection> + Clone {
+	key().and(request()).and(warp::body::bytes()).and_then(
+		|key: Option<String>, req: Request, body: Bytes| async move {
+			check(key, req, &body)?;
+			Ok::<_, warp::Rejection>(body)
+		},
+	)
+}
+
+/// Replays the stored response if a request without a body, and with
+/// the same idempotency key, has already been processed.
+pub fn claim() -> impl Filter<Extract = (), Error = warp::Rejection> + Clone {
+	key()
+		.and(request())
+		.and_then(|key: Option<String>, req: Request| async move { check(key, req, &[]) })
+		.untuple_one()
+}
+
+/// Periodically removes the expired responses from the store
+pub async fn expiry() {
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Create the interval ticker
+	let mut interval = tokio::time::interval(EXPIRY_INTERVAL);
+	// Loop indefinitely
+	loop {
+		// Wait for the timer
+		interval.tick().await;
+		// Remove the expired responses
+		store().expire(opt.idempotency_window);
+	}
+}
+
+fn key() -> impl Filter<Extract = (Option<String>,), Error = warp::Rejection> + Clone {
+	warp::method()
+		.and(warp::header::optional::<String>(HEADER))
+		.and(warp::header::optional::<String>("authorization"))
+		.and(warp::header::optional::<String>("ns"))
+		.and(warp::header::optional::<String>("db"))
+		.map(
+			|method: Method,
+			 key: Option<String>,
+			 au: Option<String>,
+			 ns: Option<String>,
+			 db: Option<String>| match (method, key) {
+				// Idempotency keys are scoped to the credentials
+				(Method::POST | Method::PUT | Method::PATCH | Method::DELETE, Some(key)) => {
+					Some(format!(
+						"{}\n{}\n{}\n{key}",
+						ns.unwrap_or_default(),
+						db.unwrap_or_default(),
+						au.unwrap_or_default(),
+					))
+				}
+				// Other requests are always processed
+				_ => None,
+			},
+		)
+}
+
+/// Notes the idempotency key of a request before it is processed
+fn pending() -> impl Filter<Extract = (Option<Pending>,), Error = warp::Rejection> + Clone {
+	key().and(request()).map(|key: Option<String>, req: Request| {
+		key.map(|key| Pending {
+			key,
+			route: route(&req),
+			since: store().claims,
+		})
+	})
+}
+
+/// The method, path, and query of a request
+type Request = (Method, FullPath, String);
+
+fn request() -> impl Filter<Extract = (Request,), Error = warp::Rejection> + Clone {
+	warp::method()
+		.and(warp::path::full())
+		.and(warp::query::raw().or(warp::any().map(String::new)).unify())
+		.map(|method: Method, path: FullPath, query: String| (method, path, query))
+}
+
+fn route((method, path, query): &Request) -> u64 {
+	let mut hasher = DefaultHasher::new();
+	method.as_str().hash(&mut hasher);
+	path.as_str().hash(&mut hasher);
+	query.hash(&mut hasher);
+	hasher.finish()
+}
+
+fn hash((method, path, query): &Request, body: &[u8]) -> u64 {
+	let mut hasher = DefaultHasher::new();
+	method.as_str().hash(&mut hasher);
+	path.as_str().hash(&mut hasher);
+	query.hash(&mut hasher);
+	body.hash(&mut hasher);
+	hasher.finish()
+}
+
+fn check(key: Option<String>, req: Request, body: &[u8]) -> Result<(), warp::Rejection> {
+	// Requests without an idempotency key are always processed
+	let key = match key {
+		Some(key) => key,
+		None => return Ok(()),
+	};
+	// Get local copy of options
+	let opt = CF.get().unwrap();
+	// Check and claim the key in a single step
+	match store().claim(&key, hash(&req, body), route(&req), opt.idempotency_window) {
+		Ok(Claim::Process) => Ok(()),
+		Ok(Claim::Replay(res)) => Err(warp::reject::custom(Replay(res))),
+		Err(err) => Err(warp::reject::custom(err)),
+	}
+}
+
+/// Checks if a request was rejected because another request holds the idempotency key
+fn conflicted(err: &warp::Rejection) -> bool {
+	matches!(
+		err.find::<Error>(),
+		Some(Error::IdempotencyConflict | Error::IdempotencyMismatch | Error::IdempotencyFull)
+	)
+}
+
+async fn record(
+	req: Option<Pending>,
+	res: Result<Response, warp::Rejection>,
+) -> Result<Response, warp::Rejection> {
+	// Requests without an idempotency key are not stored
+	let req = match req {
+		Some(req) => req,
+		None => return res,
+	};
+	let res = match res {
+		// The request was already processed
+		Err(err) if err.find::<Replay>().is_some() => {
+			return Ok(err.find::<Replay>().unwrap().0.response());
+		}
+		// Another request holds the idempotency key
+		Err(err) if conflicted(&err) => return Err(err),
+		// The request failed, so it can be retried
+		Err(err) => {
+			store().release(&req);
+			return Err(err);
+		}
+		// The request did not succeed, so it can be retried
+		Ok(res) if !res.status().is_success() => {
+			store().release(&req);
+			return Ok(res);
+		}
+		// The request succeeded
+		Ok(res) => res,
+	};
+	// Read the full response body
+	let (parts, body) = res.into_parts();
+	let body = match warp::hyper::body::to_bytes(body).await {
+		Ok(body) => body,
+		Err(_) => {
+			store().release(&req);
+			return Ok(StatusCode::INTERNAL_SERVER_ERROR.into_response());
+		}
+	};
+	// Store the response
+	store().complete(
+		&req.key,
+		Stored {
+			status: parts.status,
+			headers: parts.headers.clone(),
+			body: body.clone(),
+		},
+	);
+	// Return the response
+	Ok(Response::from_parts(parts, body.into()))
+}
+
+#[cfg(test)]
+mod tests {
+	use super::*;
+	use crate::cli::Config;
+	use crate::net::client_ip::ClientIp;
+
+	const WINDOW: Duration = Duration::from_secs(60);
+
+	fn stored() -> Stored {
+		Stored {
+			status: StatusCode::OK,
+			headers: HeaderMap::new(),
+			body: Bytes::from_static(b"test"),
+		}
+	}
+
+	#[test]
+	fn claim_concurrent() {
+		let mut store = Store::default();
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Err(Error::IdempotencyConflict)));
+		assert!(matches!(store.claim("other", 1, 1, WINDOW), Ok(Claim::Process)));
+	}
+
+	#[test]
+	fn claim_completed() {
+		let mut store = Store::default();
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		store.complete("key", stored());
+		match store.claim("key", 1, 1, WINDOW) {
+			Ok(Claim::Replay(res)) => assert_eq!(res.body, Bytes::from_static(b"test")),
+			v => panic!("expected a replay, found {v:?}"),
+		}
+	}
+
+	#[test]
+	fn claim_mismatch() {
+		let mut store = Store::default();
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		assert!(matches!(store.claim("key", 2, 1, WINDOW), Err(Error::IdempotencyMismatch)));
+		store.complete("key", stored());
+		assert!(matches!(store.claim("key", 2, 1, WINDOW), Err(Error::IdempotencyMismatch)));
+	}
+
+	fn pending(store: &Store, route: u64) -> Pending {
+		Pending {
+			key: "key".to_owned(),
+			route,
+			since: store.claims,
+		}
+	}
+
+	#[test]
+	fn claim_released() {
+		let mut store = Store::default();
+		let req = pending(&store, 1);
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		store.release(&req);
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		// Completed requests are not released
+		store.complete("key", stored());
+		store.release(&req);
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Replay(_))));
+	}
+
+	#[test]
+	fn claim_released_by_another_request() {
+		let mut store = Store::default();
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		// A request received after the key was claimed does not release it
+		store.release(&pending(&store, 1));
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Err(Error::IdempotencyConflict)));
+		// A request for another route does not release it
+		let mut store = Store::default();
+		let req = pending(&store, 2);
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		store.release(&req);
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Err(Error::IdempotencyConflict)));
+	}
+
+	#[test]
+	fn claim_expired() {
+		let mut store = Store::default();
+		assert!(matches!(store.claim("key", 1, 1, Duration::ZERO), Ok(Claim::Process)));
+		store.complete("key", stored());
+		// The key can be reused for any request once expired
+		assert!(matches!(store.claim("key", 2, 1, Duration::ZERO), Ok(Claim::Process)));
+		store.expire(Duration::ZERO);
+		assert!(store.entries.is_empty());
+	}
+
+	#[test]
+	fn claim_full() {
+		let mut store = Store::default();
+		for i in 0..MAX_ENTRIES {
+			assert!(matches!(store.claim(&i.to_string(), 1, 1, WINDOW), Ok(Claim::Process)));
+		}
+		// Requests which are being processed are never evicted
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Err(Error::IdempotencyFull)));
+		// Completed requests are evicted when the store is full
+		store.complete("0", stored());
+		assert!(matches!(store.claim("key", 1, 1, WINDOW), Ok(Claim::Process)));
+		assert_eq!(store.entries.len(), MAX_ENTRIES);
+		assert!(!store.entries.contains_key("0"));
+	}
+
+	#[tokio::test]
+	async fn release_failed_request() {
+		let _ = CF.set(Config {
+			bind: "127.0.0.1:8000".parse().unwrap(),
+			path: "memory".to_owned(),
+			client_ip: ClientIp::None,
+			user: "root".to_owned(),
+			pass: None,
+			crt: None,
+			key: None,
+			idempotency_window: WINDOW,
+		});
+		// The GET route adds a method rejection to every failed POST
+		let routes = wrap(warp::get().and(warp::path("fail")).map(warp::reply).or(
+			warp::post().and(warp::path("fail")).and(body()).and_then(|_: Bytes| async {
+				Err::<String, _>(warp::reject::custom(Error::InvalidAuth))
+			}),
+		));
+		for _ in 0..2 {
+			let res = warp::test::request()
+				.method("POST")
+				.path("/fail")
+				.header(HEADER, "release_failed_request")
+				.body("test")
+				.filter(&routes)
+				.await;
+			// The key is released, so the request is processed again
+			let err = res.err().unwrap();
+			assert!(matches!(err.find::<Error>(), Some(Error::InvalidAuth)));
+		}
+	}
+}
@@ -1,10 +1,12 @@
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::net::idempotency;
 use crate::net::input::bytes_to_utf8;
 use crate::net::output;
 use crate::net::session;
 use bytes::Bytes;
 use surrealdb::dbs::Session;
+use surrealdb::kvs::Priority;
 use warp::http;
 use warp::Filter;
 
@@ -17,7 +19,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::post())
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(session::build())
 		.and_then(handler)
 }
@@ -34,13 +36,15 @@ async fn handler(
 			let db = DB.get().unwrap();
 			// Convert the body to a byte slice
 			let sql = bytes_to_utf8(&sql)?;
+			// Imports are shed first when the datastore is overloaded
+			let session = session.with_priority(Priority::Low);
 			// Execute the sql query in the database
 			match db.execute(sql, &session, None).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// Return nothing
@@ -1,5 +1,6 @@
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::net::idempotency;
 use crate::net::input::bytes_to_utf8;
 use crate::net::output;
 use crate::net::params::{Param, Params};
@@ -48,7 +49,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(path!("key" / Param).and(warp::path::end()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(create_all);
@@ -58,7 +59,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(path!("key" / Param).and(warp::path::end()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(update_all);
@@ -66,9 +67,10 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 	let modify = warp::any()
 		.and(warp::patch())
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
+		.and(warp::header::optional::<String>(http::header::CONTENT_TYPE.as_str()))
 		.and(path!("key" / Param).and(warp::path::end()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(modify_all);
@@ -78,6 +80,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(path!("key" / Param).and(warp::path::end()))
 		.and(warp::query())
+		.and(idempotency::claim())
 		.and(session::build())
 		.and_then(delete_all);
 	// Specify route
@@ -100,7 +103,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(path!("key" / Param / Param).and(warp::path::end()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(create_one);
@@ -110,7 +113,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(path!("key" / Param / Param).and(warp::path::end()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(update_one);
@@ -118,9 +121,10 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 	let modify = warp::any()
 		.and(warp::patch())
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
+		.and(warp::header::optional::<String>(http::header::CONTENT_TYPE.as_str()))
 		.and(path!("key" / Param / Param).and(warp::path::end()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(modify_one);
@@ -130,6 +134,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(path!("key" / Param / Param).and(warp::path::end()))
 		.and(warp::query())
+		.and(idempotency::claim())
 		.and(session::build())
 		.and_then(delete_one);
 	// Specify route
@@ -169,9 +174,9 @@ async fn select_all(
 	match db.execute(sql.as_str(), &session, Some(vars)).await {
 		Ok(ref res) => match output.as_ref() {
 			// Simple serialization
-			"application/json" => Ok(output::json(&output::simplify(res))),
-			"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-			"application/pack" => Ok(output::pack(&output::simplify(res))),
+			"application/json" => Ok(output::json(&output::responses(&res))),
+			"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+			"application/pack" => Ok(output::pack(&output::responses(&res))),
 			// Internal serialization
 			"application/surrealdb" => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -208,9 +213,9 @@ async fn create_all(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -250,9 +255,9 @@ async fn update_all(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -268,6 +273,7 @@ async fn update_all(
 
 async fn modify_all(
 	output: String,
+	content: Option<String>,
 	table: Param,
 	body: Bytes,
 	params: Params,
@@ -281,7 +287,7 @@ async fn modify_all(
 	match surrealdb::sql::value(data) {
 		Ok(data) => {
 			// Specify the request statement
-			let sql = "UPDATE type::table($table) MERGE $data";
+			let sql = format!("UPDATE type::table($table) {}", modify_clause(content));
 			// Specify the request variables
 			let vars = map! {
 				String::from("table") => Value::from(table),
@@ -289,12 +295,12 @@ async fn modify_all(
 				=> params.parse()
 			};
 			// Execute the query and return the result
-			match db.execute(sql, &session, Some(vars)).await {
+			match db.execute(&sql, &session, Some(vars)).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -327,9 +333,9 @@ async fn delete_all(
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match output.as_ref() {
 			// Simple serialization
-			"application/json" => Ok(output::json(&output::simplify(res))),
-			"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-			"application/pack" => Ok(output::pack(&output::simplify(res))),
+			"application/json" => Ok(output::json(&output::responses(&res))),
+			"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+			"application/pack" => Ok(output::pack(&output::responses(&res))),
 			// Internal serialization
 			"application/surrealdb" => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -368,9 +374,9 @@ async fn select_one(
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match output.as_ref() {
 			// Simple serialization
-			"application/json" => Ok(output::json(&output::simplify(res))),
-			"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-			"application/pack" => Ok(output::pack(&output::simplify(res))),
+			"application/json" => Ok(output::json(&output::responses(&res))),
+			"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+			"application/pack" => Ok(output::pack(&output::responses(&res))),
 			// Internal serialization
 			"application/surrealdb" => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -414,9 +420,9 @@ async fn create_one(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -463,9 +469,9 @@ async fn update_one(
 			match db.execute(sql, &session, Some(vars)).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -481,6 +487,7 @@ async fn update_one(
 
 async fn modify_one(
 	output: String,
+	content: Option<String>,
 	table: Param,
 	id: Param,
 	body: Bytes,
@@ -500,7 +507,7 @@ async fn modify_one(
 	match surrealdb::sql::value(data) {
 		Ok(data) => {
 			// Specify the request statement
-			let sql = "UPDATE type::thing($table, $id) MERGE $data";
+			let sql = format!("UPDATE type::thing($table, $id) {}", modify_clause(content));
 			// Specify the request variables
 			let vars = map! {
 				String::from("table") => Value::from(table),
@@ -509,12 +516,12 @@ async fn modify_one(
 				=> params.parse()
 			};
 			// Execute the query and return the result
-			match db.execute(sql, &session, Some(vars)).await {
+			match db.execute(&sql, &session, Some(vars)).await {
 				Ok(res) => match output.as_ref() {
 					// Simple serialization
-					"application/json" => Ok(output::json(&output::simplify(res))),
-					"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-					"application/pack" => Ok(output::pack(&output::simplify(res))),
+					"application/json" => Ok(output::json(&output::responses(&res))),
+					"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+					"application/pack" => Ok(output::pack(&output::responses(&res))),
 					// Internal serialization
 					"application/surrealdb" => Ok(output::full(&res)),
 					// An incorrect content-type was requested
@@ -554,9 +561,9 @@ async fn delete_one(
 	match db.execute(sql, &session, Some(vars)).await {
 		Ok(res) => match output.as_ref() {
 			// Simple serialization
-			"application/json" => Ok(output::json(&output::simplify(res))),
-			"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-			"application/pack" => Ok(output::pack(&output::simplify(res))),
+			"application/json" => Ok(output::json(&output::responses(&res))),
+			"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+			"application/pack" => Ok(output::pack(&output::responses(&res))),
 			// Internal serialization
 			"application/surrealdb" => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -566,3 +573,15 @@ async fn delete_one(
 		Err(err) => Err(warp::reject::custom(Error::from(err))),
 	}
 }
+
+/// Selects the update clause for a PATCH request based on its content type
+fn modify_clause(content: Option<String>) -> &'static str {
+	match content.as_deref().and_then(|v| v.split(';').next()).map(str::trim) {
+		// A set of JSON Patch (RFC 6902) operations
+		Some("application/json-patch+json") => "PATCH $data",
+		// A JSON Merge Patch (RFC 7386) document
+		Some("application/merge-patch+json") => "CONTENT patch::merge($this, $data)",
+		// The default SurrealDB merge semantics
+		_ => "MERGE $data",
+	}
+}
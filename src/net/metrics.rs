@@ -0,0 +1,58 @@
+use crate::dbs::DB;
+use crate::err::Error;
+use crate::net::session;
+use std::fmt::Write;
+use surrealdb::dbs::Session;
+use warp::http;
+use warp::Filter;
+
+#[allow(opaque_hidden_inferred_bound)]
+pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejection> + Clone {
+	warp::path("metrics")
+		.and(warp::path::end())
+		.and(warp::get())
+		.and(session::build())
+		.and_then(handler)
+}
+
+async fn handler(session: Session) -> Result<impl warp::Reply, warp::Rejection> {
+	// Check the permissions
+	match session.au.is_kv() {
+		true => {
+			// Get the datastore reference
+			let db = DB.get().unwrap();
+			// Get the resources used by each namespace
+			let usage = db.usage();
+			// Output the usage in the Prometheus text format
+			let mut out = String::new();
+			let metrics = [
+				("time_seconds_total", "counter", "Time spent processing statements"),
+				("reads_total", "counter", "Records read from the storage engine"),
+				("writes_total", "counter", "Records written or deleted"),
+				("written_bytes_total", "counter", "Bytes of record data written"),
+			];
+			for (name, kind, help) in metrics {
+				let _ = writeln!(out, "# HELP surrealdb_namespace_{name} {help}");
+				let _ = writeln!(out, "# TYPE surrealdb_namespace_{name} {kind}");
+				for (ns, v) in usage.iter() {
+					let val = match name {
+						"time_seconds_total" => v.time.as_secs_f64().to_string(),
+						"reads_total" => v.reads.to_string(),
+						"writes_total" => v.writes.to_string(),
+						_ => v.bytes.to_string(),
+					};
+					let ns = ns.replace('\\', "\\\\").replace('"', "\\\"").replace('\n', "\\n");
+					let _ = writeln!(out, "surrealdb_namespace_{name}{{ns=\"{ns}\"}} {val}");
+				}
+			}
+			// Return the metrics
+			Ok(warp::reply::with_header(
+				out,
+				http::header::CONTENT_TYPE,
+				"text/plain; version=0.0.4",
+			))
+		}
+		// There was an error with permissions
+		_ => Err(warp::reject::custom(Error::InvalidAuth)),
+	}
+}
@@ -3,11 +3,13 @@ mod export;
 mod fail;
 mod head;
 mod health;
+mod idempotency;
 mod import;
 mod index;
 mod input;
 mod key;
 mod log;
+mod metrics;
 mod output;
 mod params;
 mod rpc;
@@ -39,16 +41,21 @@ pub async fn init() -> Result<(), Error> {
 		.or(signin::config())
 		// Export endpoint
 		.or(export::config())
-		// Import endpoint
-		.or(import::config())
+		// Metrics endpoint
+		.or(metrics::config())
 		// Backup endpoint
 		.or(sync::config())
 		// RPC query endpoint
 		.or(rpc::config())
-		// SQL query endpoint
-		.or(sql::config())
-		// API query endpoint
-		.or(key::config())
+		// Endpoints which support idempotency keys
+		.or(idempotency::wrap(
+			// Import endpoint
+			import::config()
+				// SQL query endpoint
+				.or(sql::config())
+				// API query endpoint
+				.or(key::config()),
+		))
 		// Catch all errors
 		.recover(fail::recover)
 		// End routes setup
@@ -67,6 +74,9 @@ pub async fn init() -> Result<(), Error> {
 	// Get local copy of options
 	let opt = CF.get().unwrap();
 
+	// Expire the stored idempotent responses
+	tokio::spawn(idempotency::expiry());
+
 	info!("Starting web server on {}", &opt.bind);
 
 	if let (Some(c), Some(k)) = (&opt.crt, &opt.key) {
@@ -2,6 +2,7 @@ use http::header::{HeaderValue, CONTENT_TYPE};
 use http::StatusCode;
 use serde::Serialize;
 use serde_json::Value as Json;
+use surrealdb::dbs::Response;
 use surrealdb::sql;
 
 pub enum Output {
@@ -67,6 +68,31 @@ pub fn simplify<T: Serialize>(v: T) -> Json {
 	sql::to_value(v).unwrap().into()
 }
 
+/// Convert and simplify the query responses into JSON, adding the kind
+/// of error to any responses which failed, any warnings, and the records
+/// which each statement read and wrote, if they were requested
+pub fn responses(res: &[Response]) -> Json {
+	let mut out = simplify(res);
+	if let Json::Array(v) = &mut out {
+		for (v, r) in v.iter_mut().zip(res) {
+			if let Json::Object(v) = v {
+				if let Err(e) = &r.result {
+					v.insert("kind".to_owned(), e.kind().into());
+				}
+				if !r.warnings.is_empty() {
+					v.insert("warnings".to_owned(), r.warnings.clone().into());
+				}
+				if let Some(s) = &r.stats {
+					v.insert("reads".to_owned(), s.reads.into());
+					v.insert("writes".to_owned(), s.writes.into());
+					v.insert("indexes".to_owned(), s.indexes.clone().into());
+				}
+			}
+		}
+	}
+	out
+}
+
 impl warp::Reply for Output {
 	fn into_response(self) -> warp::reply::Response {
 		match self {
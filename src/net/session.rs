@@ -23,6 +23,14 @@ pub fn build() -> impl Filter<Extract = (Session,), Error = warp::Rejection> + C
 	let conf = conf.and(warp::header::optional::<String>("ns"));
 	// Add database header
 	let conf = conf.and(warp::header::optional::<String>("db"));
+	// Add dry run header
+	let conf = conf.and(warp::header::optional::<String>("dry-run"));
+	// Add transaction header
+	let conf = conf.and(warp::header::optional::<String>("transaction"));
+	// Add warnings header
+	let conf = conf.and(warp::header::optional::<String>("warnings"));
+	// Add statistics header
+	let conf = conf.and(warp::header::optional::<String>("stats"));
 	// Process all headers
 	conf.and_then(process)
 }
@@ -34,11 +42,23 @@ async fn process(
 	id: Option<String>,
 	ns: Option<String>,
 	db: Option<String>,
+	dr: Option<String>,
+	tx: Option<String>,
+	wn: Option<String>,
+	st: Option<String>,
 ) -> Result<Session, warp::Rejection> {
 	let kvs = DB.get().unwrap();
+	// Check if this is a dry run
+	let dr = matches!(dr, Some(v) if v.eq_ignore_ascii_case("true"));
+	// Check if the statements run in a single transaction
+	let tx = matches!(tx, Some(v) if v.eq_ignore_ascii_case("true"));
+	// Check if the statements report warnings
+	let wn = matches!(wn, Some(v) if v.eq_ignore_ascii_case("true"));
+	// Check if the statements report their statistics
+	let st = matches!(st, Some(v) if v.eq_ignore_ascii_case("true"));
 	// Create session
 	#[rustfmt::skip]
-	let mut session = Session { ip, or, id, ns, db, ..Default::default() };
+	let mut session = Session { ip, or, id, ns, db, dr, tx, wn, st, ..Default::default() };
 	// Parse the authentication header
 	match au {
 		// Basic authentication data was supplied
@@ -1,5 +1,6 @@
 use crate::dbs::DB;
 use crate::err::Error;
+use crate::net::idempotency;
 use crate::net::input::bytes_to_utf8;
 use crate::net::output;
 use crate::net::params::Params;
@@ -23,7 +24,7 @@ pub fn config() -> impl Filter<Extract = impl warp::Reply, Error = warp::Rejecti
 		.and(warp::post())
 		.and(warp::header::<String>(http::header::ACCEPT.as_str()))
 		.and(warp::body::content_length_limit(MAX))
-		.and(warp::body::bytes())
+		.and(idempotency::body())
 		.and(warp::query())
 		.and(session::build())
 		.and_then(handler);
@@ -51,9 +52,9 @@ async fn handler(
 		// Convert the response to JSON
 		Ok(res) => match output.as_ref() {
 			// Simple serialization
-			"application/json" => Ok(output::json(&output::simplify(res))),
-			"application/cbor" => Ok(output::cbor(&output::simplify(res))),
-			"application/pack" => Ok(output::pack(&output::simplify(res))),
+			"application/json" => Ok(output::json(&output::responses(&res))),
+			"application/cbor" => Ok(output::cbor(&output::responses(&res))),
+			"application/pack" => Ok(output::pack(&output::responses(&res))),
 			// Internal serialization
 			"application/surrealdb" => Ok(output::full(&res)),
 			// An incorrect content-type was requested
@@ -63,7 +63,29 @@ impl Response {
 		let mut value = match self.result {
 			Ok(data) => {
 				let value = match data {
-					Data::Query(vec) => sql::to_value(vec).unwrap(),
+					Data::Query(vec) => {
+						let mut value = sql::to_value(&vec).unwrap();
+						// Add the kind of error to any responses which failed, any warnings,
+						// and the records which each statement read and wrote, if requested
+						if let Value::Array(v) = &mut value {
+							for (v, r) in v.iter_mut().zip(&vec) {
+								if let Value::Object(v) = v {
+									if let Err(e) = &r.result {
+										v.insert("kind".to_owned(), e.kind().into());
+									}
+									if !r.warnings.is_empty() {
+										v.insert("warnings".to_owned(), r.warnings.clone().into());
+									}
+									if let Some(s) = &r.stats {
+										v.insert("reads".to_owned(), s.reads.into());
+										v.insert("writes".to_owned(), s.writes.into());
+										v.insert("indexes".to_owned(), s.indexes.clone().into());
+									}
+								}
+							}
+						}
+						value
+					}
 					Data::Live(nofication) => sql::to_value(nofication).unwrap(),
 					Data::Other(value) => value,
 				};
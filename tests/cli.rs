@@ -351,4 +351,34 @@ mod cli_integration {
 
 		assert!(run_in_dir("validate", &temp_dir).output().is_err());
 	}
+
+	#[test]
+	#[serial]
+	fn fmt_formats_surql_files() {
+		let temp_dir = assert_fs::TempDir::new().unwrap();
+
+		let statement_file = temp_dir.child("statement.surql");
+
+		statement_file.touch().unwrap();
+		statement_file.write_str("create   thing:success  SET value = 1").unwrap();
+
+		assert!(run_in_dir("fmt --check", &temp_dir).output().is_err());
+		assert!(run_in_dir("fmt", &temp_dir).output().is_ok());
+		assert!(run_in_dir("fmt --check", &temp_dir).output().is_ok());
+	}
+
+	#[test]
+	#[serial]
+	fn fmt_skips_surql_files_with_comments() {
+		let temp_dir = assert_fs::TempDir::new().unwrap();
+
+		let statement_file = temp_dir.child("statement.surql");
+
+		let content = "-- create a thing\ncreate   thing:success  SET value = 1";
+		statement_file.touch().unwrap();
+		statement_file.write_str(content).unwrap();
+
+		assert!(run_in_dir("fmt", &temp_dir).output().is_ok());
+		assert_eq!(std::fs::read_to_string(statement_file.path()).unwrap(), content);
+	}
 }